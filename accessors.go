@@ -0,0 +1,115 @@
+package rift
+
+import "reflect"
+
+// Accessors makes the struct paths go through the methods of their types:
+// setting the segment X calls SetX(value), and reading it calls X() or
+// GetX(), when the type has such methods, falling back to the field X.
+// The segment of a path set under X, as in "X.Y", is set on a copy of
+// the value read from X, which is then passed to SetX.
+//
+// A SetX method takes one argument and returns nothing or an error,
+// and a getter takes no arguments and returns one value.
+func Accessors() Option {
+	return func(c *config) {
+		c.accessors = true
+	}
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// accessorName returns the name of the field matching seg in struct type t,
+// or seg if there is none.
+func (c *config) accessorName(t reflect.Type, seg string) string {
+	if f, ok := c.fieldByName(t, seg); ok {
+		return f.Name
+	}
+	return seg
+}
+
+// getter returns the value of the getter of name on struct v, if any.
+func getter(v reflect.Value, name string) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	p := v
+	if !v.CanAddr() {
+		p = reflect.New(v.Type()).Elem()
+		p.Set(v)
+	}
+	p = p.Addr()
+	for _, n := range []string{name, "Get" + name} {
+		m := p.MethodByName(n)
+		if m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+			return m.Call(nil)[0], true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// accessorType returns the type read by the getter of name on struct
+// type t, or else the type taken by its setter, if it has either.
+func accessorType(t reflect.Type, name string) (reflect.Type, bool) {
+	p := reflect.PointerTo(t)
+	for _, n := range []string{name, "Get" + name} {
+		if m, ok := p.MethodByName(n); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 {
+			return m.Type.Out(0), true
+		}
+	}
+	m, ok := p.MethodByName("Set" + name)
+	if !ok {
+		return nil, false
+	}
+	if mt := m.Type; mt.NumIn() != 2 || mt.NumOut() > 1 || mt.NumOut() == 1 && mt.Out(0) != errorType {
+		return nil, false
+	}
+	return m.Type.In(1), true
+}
+
+// setAccessor sets val at the rest of the path under the segment name
+// of struct dst through its setter, and reports whether there is one.
+func (s *setter) setAccessor(dst, val reflect.Value, name, rest string) (old any, ok bool, err error) {
+	if !dst.CanAddr() || !dst.CanInterface() {
+		return nil, false, nil
+	}
+	m := dst.Addr().MethodByName("Set" + name)
+	if !m.IsValid() {
+		return nil, false, nil
+	}
+	t := m.Type()
+	if t.NumIn() != 1 || t.NumOut() > 1 || t.NumOut() == 1 && t.Out(0) != errorType {
+		return nil, false, nil
+	}
+	cur, found := getter(dst, name)
+	if !found {
+		if f, ok := dst.Type().FieldByName(name); ok && f.IsExported() {
+			if fv, err := dst.FieldByIndexErr(f.Index); err == nil {
+				cur, found = fv, true
+			}
+		}
+	}
+	in := reflect.New(t.In(0)).Elem()
+	s.segs = append(s.segs, name)
+	if rest == "" {
+		if found {
+			old = valueOf(cur)
+		}
+		if val.IsValid() {
+			if !val.Type().AssignableTo(in.Type()) {
+				return nil, true, ErrType
+			}
+			in.Set(val)
+		}
+	} else {
+		if found && cur.Type().AssignableTo(in.Type()) {
+			in.Set(cur)
+		}
+		if old, err = s.set(in, val, rest); err != nil {
+			return nil, true, err
+		}
+	}
+	if out := m.Call([]reflect.Value{in}); len(out) == 1 && !out[0].IsNil() {
+		return nil, true, out[0].Interface().(error)
+	}
+	return old, true, nil
+}
@@ -0,0 +1,90 @@
+package rift_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+type Account struct {
+	Email   string
+	Limits  Limits
+	balance int
+	Sets    int
+}
+
+type Limits struct {
+	Daily int
+}
+
+func (a *Account) SetEmail(v string) error {
+	if !strings.Contains(v, "@") {
+		return errors.New("invalid email")
+	}
+	a.Email = strings.ToLower(v)
+	a.Sets++
+	return nil
+}
+
+func (a *Account) SetLimits(v Limits) {
+	a.Limits = v
+	a.Sets++
+}
+
+func (a *Account) Balance() int {
+	return a.balance
+}
+
+func (a *Account) SetBalance(v int) {
+	a.balance = v
+}
+
+func TestAccessors(t *testing.T) {
+
+	var a Account
+
+	c, err := rift.TrySetPath(&a, "Email", "John@X.com", rift.Accessors())
+	assertEqual(t, nil, err, "setter")
+	assertEqual(t, rift.Change{Path: "Email", Type: "string", New: "John@X.com", Old: ""}, c, "setter")
+	assertEqual(t, "john@x.com", a.Email, "setter")
+
+	_, err = rift.TrySetPath(&a, "Email", "john", rift.Accessors())
+	assertEqual(t, "rift: Email: invalid email", err.Error(), "setter error")
+
+	c, err = rift.TrySetPath(&a, "Limits.Daily", 5, rift.Accessors())
+	assertEqual(t, nil, err, "nested")
+	assertEqual(t, rift.Change{Path: "Limits.Daily", Type: "int", New: 5, Old: 0}, c, "nested")
+	assertEqual(t, Limits{Daily: 5}, a.Limits, "nested")
+	assertEqual(t, 2, a.Sets, "nested through setter")
+
+	c, err = rift.TrySetPath(&a, "Balance", 100, rift.Accessors())
+	assertEqual(t, nil, err, "property")
+	assertEqual(t, rift.Change{Path: "Balance", Type: "int", New: 100, Old: 0}, c, "property")
+
+	v, err := rift.GetPath(&a, "Balance", rift.Accessors())
+	assertEqual(t, nil, err, "getter")
+	assertEqual(t, 100, v, "getter")
+
+	_, err = rift.GetPath(&a, "Balance")
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "no getter without option")
+
+	tc, err := rift.SetAs(&a, "Balance", 200, rift.Accessors())
+	assertEqual(t, nil, err, "typed setter")
+	assertEqual(t, rift.TypedChange[int]{Path: "Balance", Type: "int", New: 200, Old: 100}, tc, "typed setter")
+
+	b, err := rift.GetAs[int](&a, "Balance", rift.Accessors())
+	assertEqual(t, nil, err, "typed getter")
+	assertEqual(t, 200, b, "typed getter")
+
+	_, err = rift.SetAs(&a, "Balance", "x", rift.Accessors())
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "typed accessor mismatch")
+
+	_, err = rift.GetAs[int](&a, "Balance")
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "no typed getter without option")
+
+	rift.SetPath(&a, "Email", "direct")
+	assertEqual(t, "direct", a.Email, "field without option")
+	assertEqual(t, 2, a.Sets, "field without option")
+}
@@ -0,0 +1,461 @@
+package rift
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Option configures how values are set.
+type Option func(*config)
+
+type config struct {
+	replace    bool
+	noop       noopMode
+	fullTypes  bool
+	structural bool
+	flatten    bool
+	match      matchMode
+	atomic     bool
+	undo       bool // Record the structural changes to undo them.
+	version    string
+	accessors  bool
+	redact     bool
+	secrets    []string // Patterns of the paths redacted.
+	audit      *auditor
+	lenient    bool // Ignore the paths not found. See [SetPath].
+}
+
+type noopMode int
+
+const (
+	noopOff noopMode = iota
+	noopFlag
+	noopSkip
+)
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Replace makes the nodes with children authoritative over their paths:
+// map keys not present in their children are removed, and slices are
+// truncated after the highest index present in their children.
+// Each removal is recorded as an [OpDelete] change.
+func Replace() Option {
+	return func(c *config) {
+		c.replace = true
+	}
+}
+
+// FlagNoop compares the value at each path with the new one before setting it,
+// and when they are equal it does not set it and flags the change as Noop.
+// Values are equal when their Equal method, as in time.Time, reports so,
+// or else when they are deeply equal.
+func FlagNoop() Option {
+	return func(c *config) {
+		c.noop = noopFlag
+	}
+}
+
+// SkipNoop is like [FlagNoop], but the Noop changes are also left
+// out of the changes returned by [Set] and [Apply].
+func SkipNoop() Option {
+	return func(c *config) {
+		c.noop = noopSkip
+	}
+}
+
+// FullTypes reports the full Go type of the values, as in []main.Address
+// or *time.Time, in the GoType of the nodes returned by [Get] and of the
+// changes, which also get the Kind of that type.
+// The type of a path holding an interface is the type of its value.
+func FullTypes() Option {
+	return func(c *config) {
+		c.fullTypes = true
+	}
+}
+
+// Structural records the pointers allocated ([OpAlloc]), the slices and maps
+// created ([OpMake]), the slices grown ([OpGrow]) and the map keys added
+// ([OpAdd]) to set a value,
+// as changes preceding the change of the value, so that [ChangeSet.Invert]
+// reverts them exactly. The Old value of a value in a newly allocated
+// pointer is then its zero value instead of nil.
+//
+// [SetPath] and [TrySetPath] return only the change of the value.
+// [Apply] ignores structural changes, as setting the values recreates them.
+func Structural() Option {
+	return func(c *config) {
+		c.structural = true
+	}
+}
+
+// Atomic makes [Apply] and [Set] all or nothing: when a path fails,
+// the changes applied so far are undone, leaving dst as it was,
+// and no changes are returned.
+func Atomic() Option {
+	return func(c *config) {
+		c.atomic = true
+	}
+}
+
+// Version increments the integer at path after each batch of [Apply]
+// or [Set] that changes something, recording the change last.
+// Paired with a [Test] of the version, it makes optimistic concurrency:
+//
+//	rift.Apply(&order, []rift.Node{
+//		rift.Test("Version", 3),
+//		rift.Path("Status", "shipped"),
+//	}, rift.Version("Version"))
+func Version(path string) Option {
+	return func(c *config) {
+		c.version = path
+	}
+}
+
+// Apply sets values to dst based on the provided nodes, as [Set] does
+// for each of them, and returns the changes. The values are validated
+// first, and if any breaks a rule, none is set. See [ValidationError].
+// On error, it returns the changes applied so far, unless [Atomic] is used.
+// Batches with [Test] nodes are always atomic.
+func Apply(dst any, ns []Node, opts ...Option) (ChangeSet, error) {
+	return newConfig(opts).apply(dst, ns)
+}
+
+func (c *config) apply(dst any, ns []Node) (ChangeSet, error) {
+	cs, err := c.applyAll(dst, ns)
+	if c.audit != nil && !c.undo {
+		c.audit.log(cs, err)
+	}
+	return cs, err
+}
+
+func (c *config) applyAll(dst any, ns []Node) (ChangeSet, error) {
+	if !c.undo {
+		if err := c.validate(dst, ns); err != nil {
+			return nil, err
+		}
+	}
+	if !c.undo && (c.atomic || slices.ContainsFunc(ns, hasTest)) {
+		return c.applyAtomic(dst, ns)
+	}
+	var chgs ChangeSet
+	for _, n := range ns {
+		cs, err := c.set(dst, n)
+		chgs = append(chgs, cs...)
+		if err != nil {
+			return chgs, err
+		}
+	}
+	if c.version != "" && slices.ContainsFunc(chgs, func(c Change) bool { return !c.Noop }) {
+		cs, err := c.bump(dst)
+		chgs = append(chgs, cs...)
+		if err != nil {
+			return chgs, err
+		}
+	}
+	return chgs, nil
+}
+
+// bump increments the integer at the version path.
+func (c *config) bump(dst any) ([]Change, error) {
+	v, err := c.getPath(reflect.ValueOf(dst), c.version)
+	if err != nil {
+		return nil, pathError(c.version, err)
+	}
+	n := reflect.New(derefType(v.Type())).Elem()
+	if v = derefValue(v); v.IsValid() && v.Type() == n.Type() {
+		n.Set(v)
+	}
+	switch n.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n.SetInt(n.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n.SetUint(n.Uint() + 1)
+	default:
+		return nil, &PathError{Path: c.version, Err: ErrType}
+	}
+	return c.setPath(dst, c.version, n.Interface())
+}
+
+// test returns [ErrConflict] unless the value at path equals val.
+func (c *config) test(dst any, path string, val any) error {
+	cur, _, ok := c.current(dst, path)
+	if !ok {
+		cur = reflect.Value{}
+	}
+	if !equal(cur, reflect.ValueOf(val)) {
+		return &PathError{Path: path, Err: ErrConflict}
+	}
+	return nil
+}
+
+func hasTest(n Node) bool {
+	return n.Op == OpTest || slices.ContainsFunc(n.Next, hasTest)
+}
+
+// applyAtomic applies ns recording the structural changes as well,
+// and undoes all of them if any node fails, resolving their paths
+// with the same options.
+func (c *config) applyAtomic(dst any, ns []Node) (ChangeSet, error) {
+	u := *c
+	u.undo = true
+	chgs, err := u.apply(dst, ns)
+	if err != nil {
+		u.version = ""
+		if _, uerr := u.apply(dst, chgs.Invert().Nodes()); uerr != nil {
+			return nil, errors.Join(err, uerr)
+		}
+		return nil, err
+	}
+	if !c.structural {
+		chgs = slices.DeleteFunc(chgs, func(c Change) bool { return c.Op.structural() })
+	}
+	c.redactAll(dst, chgs)
+	return chgs, nil
+}
+
+func (c *config) set(dst any, n Node) ([]Change, error) {
+	var chgs []Change
+	err := walkErr(n, func(n Node) error {
+		if n.Op == OpTest {
+			return c.test(dst, n.Path, n.Data)
+		}
+		if n.Op == OpDelete {
+			cs, err := c.deletePath(dst, n.Path)
+			chgs = append(chgs, cs...)
+			return err
+		}
+		if n.Op.structural() {
+			return nil
+		}
+		if len(n.Next) == 0 {
+			cs, err := c.setPath(dst, n.Path, n.Data)
+			if err != nil {
+				chgs = append(chgs, cs...) // Structural changes made before failing.
+				return err
+			}
+			if !cs[len(cs)-1].Noop || c.noop != noopSkip {
+				chgs = append(chgs, cs...)
+			}
+		} else if c.replace {
+			cs, err := c.prune(dst, n)
+			chgs = append(chgs, cs...)
+			return err
+		}
+		return nil
+	})
+	return chgs, err
+}
+
+// setPath sets val at path and returns the change,
+// preceded by the structural changes it caused.
+func (c *config) setPath(dst any, path string, val any) ([]Change, error) {
+	cs, err := c.setValue(dst, path, val)
+	if err == nil && c.fullTypes {
+		for i := range cs {
+			if v, err := c.getPath(reflect.ValueOf(dst), cs[i].Path); err == nil {
+				cs[i].GoType, cs[i].Kind = fullType(v)
+			}
+		}
+	}
+	c.redactAll(dst, cs)
+	return cs, err
+}
+
+func (c *config) setValue(dst any, path string, val any) ([]Change, error) {
+	v := reflect.ValueOf(val)
+	if c.noop != noopOff {
+		if cur, segs, ok := c.current(dst, path); ok && equal(cur, v) {
+			var old any
+			if cur.IsValid() {
+				old = cur.Interface()
+			}
+			return []Change{{Path: strings.Join(segs, "."), New: val, Old: old, Type: getType(v), Noop: true}}, nil
+		}
+	}
+	s := setter{config: c}
+	old, err := s.set(reflect.ValueOf(dst), v, path)
+	if err != nil && c.lenient && errors.Is(err, ErrNotFound) {
+		return append(s.chgs, Change{Path: path, New: val, Type: getType(v)}), nil
+	}
+	if err != nil {
+		return s.chgs, pathError(path, err)
+	}
+	return append(s.chgs, Change{Path: s.canonical(), New: val, Old: old, Type: getType(v)}), nil
+}
+
+// current returns the value at path, if any, and the segments of its path.
+func (c *config) current(dst any, path string) (reflect.Value, []string, bool) {
+	v, segs, err := c.lookup(reflect.ValueOf(dst), path)
+	if err != nil {
+		return v, nil, false
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return v, nil, false
+	}
+	return derefValue(v), segs, true
+}
+
+// equal reports whether a equals b, by a's Equal method or deeply.
+func equal(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if !a.CanInterface() {
+		return false
+	}
+	if m := a.MethodByName("Equal"); m.IsValid() {
+		t := m.Type()
+		if t.NumIn() == 1 && t.NumOut() == 1 && t.Out(0).Kind() == reflect.Bool && b.Type().AssignableTo(t.In(0)) {
+			return m.Call([]reflect.Value{b})[0].Bool()
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func walkErr(n Node, fn func(Node) error) error {
+	for _, v := range n.Next {
+		if err := walkErr(v, fn); err != nil {
+			return err
+		}
+	}
+	return fn(n)
+}
+
+// prune removes the elements of the value at the path of n
+// that are not present in its children.
+func (c *config) prune(dst any, n Node) ([]Change, error) {
+	cur, err := c.getPath(reflect.ValueOf(dst), n.Path)
+	if err != nil && c.lenient && errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pathError(n.Path, err)
+	}
+	keep := map[string]bool{}
+	for _, v := range n.Next {
+		keep[childKey(n.Path, v)] = true
+	}
+	var chgs []Change
+	switch cur = derefValue(cur); cur.Kind() {
+	case reflect.Slice:
+		size := 0
+		for k := range keep {
+			if i, ok := getNumber(k); ok && i >= size {
+				size = i + 1
+			}
+		}
+		if size >= cur.Len() {
+			return nil, nil
+		}
+		for i := size; i < cur.Len(); i++ {
+			chgs = append(chgs, c.deleted(joinPath(n.Path, strconv.Itoa(i)), cur.Index(i)))
+		}
+		if _, err := c.setAt(reflect.ValueOf(dst), cur.Slice(0, size), n.Path); err != nil {
+			return nil, pathError(n.Path, err)
+		}
+	case reflect.Map:
+		keys := cur.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		for _, k := range keys {
+			if k.Kind() != reflect.String || keep[k.String()] {
+				continue
+			}
+			chgs = append(chgs, c.deleted(joinPath(n.Path, k.String()), cur.MapIndex(k)))
+			cur.SetMapIndex(k, reflect.Value{})
+		}
+	}
+	c.redactAll(dst, chgs)
+	return chgs, nil
+}
+
+// deletePath removes the map key or slice element at path.
+// Slices only shrink from the tail, so removing an element also
+// removes the ones after it. Removing an absent element does nothing.
+func (c *config) deletePath(dst any, path string) ([]Change, error) {
+	i := strings.LastIndexByte(path, '.')
+	parent, key := "", path
+	if i >= 0 {
+		parent, key = path[:i], path[i+1:]
+	}
+	cur, err := c.getPath(reflect.ValueOf(dst), parent)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pathError(path, err)
+	}
+	var chgs []Change
+	switch cur = derefValue(cur); cur.Kind() {
+	case reflect.Slice:
+		n, ok := getNumber(key)
+		if !ok {
+			return nil, &PathError{Path: path, Err: ErrIndex}
+		}
+		for i := n; i < cur.Len(); i++ {
+			chgs = append(chgs, c.deleted(joinPath(parent, strconv.Itoa(i)), cur.Index(i)))
+		}
+		if n < cur.Len() {
+			if _, err := c.setAt(reflect.ValueOf(dst), cur.Slice(0, n), parent); err != nil {
+				return nil, pathError(path, err)
+			}
+		}
+	case reflect.Map:
+		k, ok := getKey(cur.Type(), key)
+		if !ok {
+			return nil, &PathError{Path: path, Err: ErrType}
+		}
+		if v := cur.MapIndex(k); v.IsValid() {
+			chgs = append(chgs, c.deleted(path, v))
+			cur.SetMapIndex(k, reflect.Value{})
+		}
+	default:
+		return nil, &PathError{Path: path, Err: ErrType}
+	}
+	c.redactAll(dst, chgs)
+	return chgs, nil
+}
+
+func (c *config) deleted(path string, v reflect.Value) Change {
+	chg := Change{Op: OpDelete, Path: path}
+	if c.fullTypes {
+		chg.GoType, chg.Kind = fullType(v)
+	}
+	if v = derefValue(v); v.IsValid() {
+		chg.Old = v.Interface()
+	}
+	chg.Type = getType(v)
+	return chg
+}
+
+// fullType returns the type of v, or of its value if v is an interface,
+// and the kind of that type without pointers.
+func fullType(v reflect.Value) (goType, kind string) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", ""
+	}
+	return v.Type().String(), derefType(v.Type()).Kind().String()
+}
+
+// childKey returns the key of child under the parent path.
+func childKey(parent string, child Node) string {
+	p := child.Path
+	if parent != "" {
+		p = strings.TrimPrefix(p, parent+".")
+	}
+	k, _, _ := strings.Cut(p, ".")
+	return k
+}
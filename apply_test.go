@@ -0,0 +1,333 @@
+package rift_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestReplace(t *testing.T) {
+
+	tt := []struct {
+		Desc string
+		Give any
+		When rift.Node
+		Then any
+		Chng []rift.Change
+	}{
+		{
+			Desc: "truncate a slice after the highest index",
+			Give: &TestData{Slice: []TestData{{Int: 1}, {Int: 2}, {Int: 3}}},
+			When: rift.Node{Path: "Slice", Next: []rift.Node{{Path: "Slice.0.Int", Data: 4}}},
+			Then: &TestData{Slice: []TestData{{Int: 4}}},
+			Chng: []rift.Change{
+				{Path: "Slice.0.Int", Type: "int", New: 4, Old: 1},
+				{Op: rift.OpDelete, Path: "Slice.1", Type: "TestData", Old: TestData{Int: 2}},
+				{Op: rift.OpDelete, Path: "Slice.2", Type: "TestData", Old: TestData{Int: 3}},
+			},
+		},
+		{
+			Desc: "remove map keys",
+			Give: &TestData{Map: map[string]any{"a": 1, "b": 2, "c": 3}},
+			When: rift.Node{Path: "Map", Next: []rift.Node{{Path: "Map.b", Data: 4}}},
+			Then: &TestData{Map: map[string]any{"b": 4}},
+			Chng: []rift.Change{
+				{Path: "Map.b", Type: "int", New: 4, Old: 2},
+				{Op: rift.OpDelete, Path: "Map.a", Type: "int", Old: 1},
+				{Op: rift.OpDelete, Path: "Map.c", Type: "int", Old: 3},
+			},
+		},
+		{
+			Desc: "truncate a slice inside a map",
+			Give: &TestData{Map: map[string]any{"Arr": []any{1, 2}}},
+			When: rift.Node{Path: "Map.Arr", Next: []rift.Node{{Path: "Map.Arr.0", Data: 3}}},
+			Then: &TestData{Map: map[string]any{"Arr": []any{3}}},
+			Chng: []rift.Change{
+				{Path: "Map.Arr.0", Type: "int", New: 3, Old: 1},
+				{Op: rift.OpDelete, Path: "Map.Arr.1", Type: "int", Old: 2},
+			},
+		},
+		{
+			Desc: "nested authoritative nodes",
+			Give: &TestData{Slice: []TestData{{Map: map[string]any{"a": 1, "b": 2}}, {}}},
+			When: rift.Node{Path: "Slice", Next: []rift.Node{
+				{Path: "Slice.0", Next: []rift.Node{
+					{Path: "Slice.0.Map", Next: []rift.Node{{Path: "Slice.0.Map.a", Data: 3}}},
+				}},
+			}},
+			Then: &TestData{Slice: []TestData{{Map: map[string]any{"a": 3}}}},
+			Chng: []rift.Change{
+				{Path: "Slice.0.Map.a", Type: "int", New: 3, Old: 1},
+				{Op: rift.OpDelete, Path: "Slice.0.Map.b", Type: "int", Old: 2},
+				{Op: rift.OpDelete, Path: "Slice.1", Type: "TestData", Old: TestData{}},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		cs := rift.Set(tc.Give, tc.When, rift.Replace())
+		assertEqual(t, tc.Then, tc.Give, tc.Desc)
+		assertEqual(t, tc.Chng, cs, tc.Desc)
+	}
+
+	give := &TestData{Slice: []TestData{{Int: 1}, {Int: 2}}}
+	rift.Set(give, rift.Node{Path: "Slice", Next: []rift.Node{{Path: "Slice.0.Int", Data: 3}}})
+	assertEqual(t, &TestData{Slice: []TestData{{Int: 3}, {Int: 2}}}, give, "without Replace")
+}
+
+func TestApply(t *testing.T) {
+
+	var give TestData
+
+	cs, err := rift.Apply(&give, []rift.Node{
+		rift.Path("Int", 1),
+		rift.Path("String", 2),
+		rift.Path("Int", 3),
+	})
+
+	assertEqual(t, "rift: String: type mismatch", err.Error(), "error")
+	assertEqual(t, rift.ChangeSet{{Path: "Int", Type: "int", New: 1, Old: 0}}, cs, "changes before the error")
+	assertEqual(t, TestData{Int: 1}, give, "changes before the error")
+}
+
+func TestNoop(t *testing.T) {
+
+	day := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	type Data struct {
+		Int  int
+		Ptr  *int
+		Time time.Time
+	}
+
+	give := Data{Int: 1, Time: day}
+	nodes := []rift.Node{
+		rift.Path("Int", 1),
+		rift.Path("Ptr", 0),
+		rift.Path("Time", day.In(time.FixedZone("X", 3600))),
+		rift.Path("Int", 2),
+	}
+
+	cs, err := rift.Apply(&give, nodes, rift.FlagNoop())
+	assertEqual(t, nil, err, "flag")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Int", Type: "int", New: 1, Old: 1, Noop: true},
+		{Path: "Ptr", Type: "int", New: 0, Old: nil},
+		{Path: "Time", Type: "Time", New: nodes[2].Data, Old: day, Noop: true},
+		{Path: "Int", Type: "int", New: 2, Old: 1},
+	}, cs, "flag")
+	assertEqual(t, Data{Int: 2, Ptr: ptr(0), Time: day}, give, "noop values are not set")
+
+	give = Data{Int: 1, Time: day}
+	cs, err = rift.Apply(&give, nodes, rift.SkipNoop())
+	assertEqual(t, nil, err, "skip")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Ptr", Type: "int", New: 0, Old: nil},
+		{Path: "Int", Type: "int", New: 2, Old: 1},
+	}, cs, "skip")
+
+	c := rift.SetPath(&give, "Int", 2, rift.SkipNoop())
+	assertEqual(t, rift.Change{Path: "Int", Type: "int", New: 2, Old: 2, Noop: true}, c, "single path")
+
+	cs = rift.Set(&give, rift.Node{Next: []rift.Node{rift.Path("Int", 2), rift.Path("Int", 3)}}, rift.SkipNoop())
+	assertEqual(t, rift.ChangeSet{{Path: "Int", Type: "int", New: 3, Old: 2}}, cs, "set")
+}
+
+func TestFullTypes(t *testing.T) {
+
+	type Status string
+
+	type Address struct {
+		Street string
+	}
+
+	type Data struct {
+		Status    Status
+		Created   *time.Time
+		Addresses []Address
+		Any       any
+		Map       map[string]any
+	}
+
+	day := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+	addrs := []Address{{}, {}}
+	var give Data
+
+	cs, err := rift.Apply(&give, []rift.Node{
+		rift.Path("Status", Status("paid")),
+		rift.Path("Created", day),
+		rift.Path("Addresses", addrs),
+		rift.Path("Addresses.0.Street", "Main"),
+		rift.Path("Any", 1.5),
+		rift.Path("Map.a", []int{1}),
+		{Op: rift.OpDelete, Path: "Addresses.1"},
+	}, rift.FullTypes())
+	assertEqual(t, nil, err, "apply")
+
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Status", Type: "Status", New: Status("paid"), Old: Status(""), GoType: "rift_test.Status", Kind: "string"},
+		{Path: "Created", Type: "Time", New: day, Old: nil, GoType: "*time.Time", Kind: "struct"},
+		{Path: "Addresses", Type: "", New: addrs, Old: []Address(nil), GoType: "[]rift_test.Address", Kind: "slice"},
+		{Path: "Addresses.0.Street", Type: "string", New: "Main", Old: "", GoType: "string", Kind: "string"},
+		{Path: "Any", Type: "float64", New: 1.5, Old: nil, GoType: "float64", Kind: "float64"},
+		{Path: "Map.a", Type: "", New: []int{1}, Old: nil, GoType: "[]int", Kind: "slice"},
+		{Op: rift.OpDelete, Path: "Addresses.1", Type: "Address", Old: Address{}, GoType: "rift_test.Address", Kind: "struct"},
+	}, cs, "changes")
+
+	var types []string
+	for _, n := range rift.GetFlat(give, rift.FullTypes()) {
+		types = append(types, n.Path+" "+n.Type+" "+n.GoType)
+	}
+	assertEqual(t, []string{
+		"Status string rift_test.Status",
+		"Created struct *time.Time",
+		"Addresses.0.Street string string",
+		"Any float64 float64",
+		"Map.a.0 int int",
+	}, types, "nodes")
+}
+
+func TestStructural(t *testing.T) {
+
+	var give TestData
+
+	cs, err := rift.Apply(&give, []rift.Node{
+		rift.Path("IntPtr", 1),
+		rift.Path("SlicePtr.1.Int", 2),
+		rift.Path("Map.a.0", 3),
+		rift.Path("Map.a.2", 4),
+	}, rift.Structural())
+	assertEqual(t, nil, err, "apply")
+
+	assertEqual(t, rift.ChangeSet{
+		{Op: rift.OpAlloc, Path: "IntPtr", Type: "int"},
+		{Path: "IntPtr", Type: "int", New: 1, Old: 0},
+		{Op: rift.OpMake, Path: "SlicePtr", Type: ""},
+		{Op: rift.OpGrow, Path: "SlicePtr", Type: "", Old: 0, New: 2},
+		{Op: rift.OpAlloc, Path: "SlicePtr.1", Type: "TestData"},
+		{Path: "SlicePtr.1.Int", Type: "int", New: 2, Old: 0},
+		{Op: rift.OpMake, Path: "Map", Type: ""},
+		{Op: rift.OpAdd, Path: "Map.a", Type: ""},
+		{Op: rift.OpMake, Path: "Map.a", Type: ""},
+		{Op: rift.OpGrow, Path: "Map.a", Type: "", Old: 0, New: 1},
+		{Path: "Map.a.0", Type: "int", New: 3, Old: nil},
+		{Op: rift.OpGrow, Path: "Map.a", Type: "", Old: 1, New: 3},
+		{Path: "Map.a.2", Type: "int", New: 4, Old: nil},
+	}, cs, "structural changes")
+
+	c := rift.SetPath(&give, "Struct.Int", 5, rift.Structural())
+	assertEqual(t, rift.Change{Path: "Struct.Int", Type: "int", New: 5, Old: 0}, c, "single path")
+
+	_, err = rift.Apply(&give, cs.Invert().Nodes())
+	assertEqual(t, nil, err, "apply inverted")
+	assertEqual(t, TestData{Struct: &TestData{Int: 5}}, give, "exact revert")
+
+	_, err = rift.Apply(&give, cs.Nodes())
+	assertEqual(t, nil, err, "replay")
+	assertEqual(t, TestData{
+		IntPtr:   ptr(1),
+		SlicePtr: []*TestData{nil, {Int: 2}},
+		Struct:   &TestData{Int: 5},
+		Map:      map[string]any{"a": []any{3, nil, 4}},
+	}, give, "replay")
+}
+
+func TestAtomic(t *testing.T) {
+
+	give := TestData{Int: 1, Slice: []TestData{{Int: 2}}, Map: map[string]any{"a": 1}}
+	nodes := []rift.Node{
+		rift.Path("Int", 3),
+		rift.Path("IntPtr", 4),
+		rift.Path("Slice.2.String", "x"),
+		rift.Path("Struct.Struct.Int", 5),
+		rift.Path("Map.b", 6),
+		{Op: rift.OpDelete, Path: "Map.a"},
+		rift.Path("Struct.Int", "not an int"),
+	}
+
+	cs, err := rift.Apply(&give, nodes, rift.Atomic())
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "error")
+	assertEqual(t, rift.ChangeSet(nil), cs, "no changes")
+	assertEqual(t, TestData{Int: 1, Slice: []TestData{{Int: 2}}, Map: map[string]any{"a": 1}}, give, "untouched")
+
+	cs, err = rift.Apply(&give, nodes[:len(nodes)-1], rift.Atomic())
+	assertEqual(t, nil, err, "success")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Int", Type: "int", New: 3, Old: 1},
+		{Path: "IntPtr", Type: "int", New: 4, Old: nil},
+		{Path: "Slice.2.String", Type: "string", New: "x", Old: ""},
+		{Path: "Struct.Struct.Int", Type: "int", New: 5, Old: nil},
+		{Path: "Map.b", Type: "int", New: 6, Old: nil},
+		{Op: rift.OpDelete, Path: "Map.a", Type: "int", Old: 1},
+	}, cs, "changes without structural ones")
+
+	func() {
+		defer func() { recover() }()
+		rift.Set(&give, rift.Path("Slice.0.Int", "x"), rift.Atomic())
+	}()
+	assertEqual(t, 2, give.Slice[0].Int, "set untouched")
+
+	var a Account
+
+	_, err = rift.Apply(&a, []rift.Node{
+		rift.Path("Balance", 5),
+		rift.Path("limits.daily", 7),
+		rift.Path("Nope", 1),
+	}, rift.Accessors(), rift.IgnoreCase(), rift.Atomic())
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "undo with the options")
+	assertEqual(t, 0, a.Balance(), "undo with the options")
+	assertEqual(t, Limits{}, a.Limits, "undo with the options")
+
+	_, err = rift.Apply(&give, []rift.Node{rift.Path("Int", 8), rift.Path("Nope", 1)}, rift.Atomic(), rift.Version("Int"))
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "undo without bumping the version")
+	assertEqual(t, 3, give.Int, "undo without bumping the version")
+}
+
+func TestConditional(t *testing.T) {
+
+	type Order struct {
+		Status  string
+		Note    *string
+		Version uint
+	}
+
+	give := Order{Status: "paid", Version: 3}
+
+	_, err := rift.SetPathIf(&give, "Status", "pending", "shipped")
+	assertEqual(t, true, errors.Is(err, rift.ErrConflict), "conflict")
+	assertEqual(t, "paid", give.Status, "conflict")
+
+	c, err := rift.SetPathIf(&give, "Status", "paid", "shipped")
+	assertEqual(t, nil, err, "match")
+	assertEqual(t, rift.Change{Path: "Status", Type: "string", New: "shipped", Old: "paid"}, c, "match")
+
+	_, err = rift.SetPathIf(&give, "Note", nil, "fragile")
+	assertEqual(t, nil, err, "missing value equals nil")
+
+	cs, err := rift.Apply(&give, []rift.Node{
+		rift.Path("Status", "delivered"),
+		rift.Test("Version", uint(2)),
+	}, rift.Version("Version"))
+	assertEqual(t, true, errors.Is(err, rift.ErrConflict), "test fails the batch")
+	assertEqual(t, rift.ChangeSet(nil), cs, "test fails the batch")
+	assertEqual(t, Order{Status: "shipped", Note: ptr("fragile"), Version: 3}, give, "test fails the batch")
+
+	cs, err = rift.Apply(&give, []rift.Node{
+		rift.Test("Version", uint(3)),
+		rift.Path("Status", "delivered"),
+	}, rift.Version("Version"))
+	assertEqual(t, nil, err, "test passes")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Status", Type: "string", New: "delivered", Old: "shipped"},
+		{Path: "Version", Type: "uint", New: uint(4), Old: uint(3)},
+	}, cs, "version incremented")
+
+	cs, err = rift.Apply(&give, []rift.Node{rift.Test("Version", uint(4))}, rift.Version("Version"))
+	assertEqual(t, nil, err, "no changes")
+	assertEqual(t, rift.ChangeSet(nil), cs, "version kept without changes")
+
+	assertEqual(t, []rift.Change{{Path: "Status", Type: "string", New: "returned", Old: "delivered"}},
+		rift.SetMany(&give, rift.Test("Status", "delivered"), rift.Path("Status", "returned")), "set many")
+}
@@ -0,0 +1,143 @@
+package rift
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChangeSet is a list of changes.
+type ChangeSet []Change
+
+// Has reports whether there is a change at path.
+func (cs ChangeSet) Has(path string) bool {
+	return slices.ContainsFunc(cs, func(c Change) bool { return c.Path == path })
+}
+
+// Under returns the changes at prefix or under it.
+func (cs ChangeSet) Under(prefix string) ChangeSet {
+	var out ChangeSet
+	for _, c := range cs {
+		if isUnder(c.Path, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Match returns the changes whose paths match pattern, in which
+// a "*" segment matches any segment and a "**" segment matches
+// any number of them, as in "Limits.**" or "Users.*.Name".
+func (cs ChangeSet) Match(pattern string) ChangeSet {
+	var out ChangeSet
+	pat := strings.Split(pattern, ".")
+	for _, c := range cs {
+		if matchPath(pat, strings.Split(c.Path, ".")) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func matchPath(pat, segs []string) bool {
+	if len(pat) == 0 {
+		return len(segs) == 0
+	}
+	if pat[0] == "**" {
+		for i := range len(segs) + 1 {
+			if matchPath(pat[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 || pat[0] != "*" && pat[0] != segs[0] {
+		return false
+	}
+	return matchPath(pat[1:], segs[1:])
+}
+
+// Paths returns the paths of the changes without repetition,
+// in the order they first appear.
+func (cs ChangeSet) Paths() []string {
+	var out []string
+	for _, c := range cs {
+		if !slices.Contains(out, c.Path) {
+			out = append(out, c.Path)
+		}
+	}
+	return out
+}
+
+// Compact coalesces the changes to the same path into one,
+// placed where the path first appears, with the Old value
+// of the first change and the rest from the last one.
+func (cs ChangeSet) Compact() ChangeSet {
+	var out ChangeSet
+	at := map[string]int{}
+	for _, c := range cs {
+		i, ok := at[c.Path]
+		if !ok {
+			at[c.Path] = len(out)
+			out = append(out, c)
+			continue
+		}
+		c.Old = out[i].Old
+		out[i] = c
+	}
+	return out
+}
+
+// Effective returns the changes whose Old and New values differ.
+func (cs ChangeSet) Effective() ChangeSet {
+	var out ChangeSet
+	for _, c := range cs {
+		if c.Op != OpSet || !c.Noop && !reflect.DeepEqual(c.Old, c.New) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Invert returns the changes that undo cs, in reverse order.
+// A removal is undone by setting the removed value back, and
+// the [Structural] changes by removing what they created.
+func (cs ChangeSet) Invert() ChangeSet {
+	out := make(ChangeSet, 0, len(cs))
+	for _, c := range slices.Backward(cs) {
+		switch c.Op {
+		case OpAlloc, OpMake:
+			out = append(out, Change{Path: c.Path, Type: c.Type})
+		case OpAdd:
+			out = append(out, Change{Op: OpDelete, Path: c.Path, Type: c.Type})
+		case OpGrow:
+			if n, ok := c.Old.(int); ok {
+				out = append(out, Change{Op: OpDelete, Path: joinPath(c.Path, strconv.Itoa(n)), Type: c.Type})
+			}
+		default:
+			out = append(out, Change{
+				Path: c.Path,
+				Type: getType(reflect.ValueOf(c.Old)),
+				New:  c.Old,
+				Old:  c.New,
+			})
+		}
+	}
+	return out
+}
+
+// Nodes returns the changes as flat nodes, with New as Data,
+// to be applied with [Apply].
+func (cs ChangeSet) Nodes() []Node {
+	out := make([]Node, len(cs))
+	for i, c := range cs {
+		out[i] = Node{Op: c.Op, Path: c.Path, Data: c.New}
+	}
+	return out
+}
+
+// isUnder reports whether path is prefix or under it.
+func isUnder(path, prefix string) bool {
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+".")
+}
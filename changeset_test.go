@@ -0,0 +1,56 @@
+package rift_test
+
+import (
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestChangeSet(t *testing.T) {
+
+	give := &TestData{Int: 1, Slice: []TestData{{}, {Int: 2}}}
+
+	cs, err := rift.Apply(give, []rift.Node{
+		rift.Path("Int", 3),
+		rift.Path("Slice.0.Int", 4),
+		rift.Path("String", ""),
+		rift.Path("Int", 5),
+		{Op: rift.OpDelete, Path: "Slice.1"},
+	})
+	assertEqual(t, nil, err, "apply")
+
+	assertEqual(t, true, cs.Has("Slice.0.Int"), "has")
+	assertEqual(t, false, cs.Has("Slice.0"), "has not")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Slice.0.Int", Type: "int", New: 4, Old: 0},
+		{Op: rift.OpDelete, Path: "Slice.1", Type: "TestData", Old: TestData{Int: 2}},
+	}, cs.Under("Slice"), "under")
+	assertEqual(t, []string{"Int", "Slice.0.Int", "String", "Slice.1"}, cs.Paths(), "paths")
+	assertEqual(t, []string{"Slice.0.Int"}, cs.Match("Slice.*.Int").Paths(), "match one segment")
+	assertEqual(t, []string{"Slice.0.Int", "Slice.1"}, cs.Match("Slice.**").Paths(), "match any segments")
+	assertEqual(t, []string{"Int", "Slice.0.Int"}, cs.Match("**.Int").Paths(), "match any prefix")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Int", Type: "int", New: 5, Old: 1},
+		{Path: "Slice.0.Int", Type: "int", New: 4, Old: 0},
+		{Path: "String", Type: "string", New: "", Old: ""},
+		{Op: rift.OpDelete, Path: "Slice.1", Type: "TestData", Old: TestData{Int: 2}},
+	}, cs.Compact(), "compact")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Int", Type: "int", New: 5, Old: 1},
+		{Path: "Slice.0.Int", Type: "int", New: 4, Old: 0},
+		{Op: rift.OpDelete, Path: "Slice.1", Type: "TestData", Old: TestData{Int: 2}},
+	}, cs.Compact().Effective(), "effective")
+
+	inv := cs.Invert()
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Slice.1", Type: "TestData", New: TestData{Int: 2}, Old: nil},
+		{Path: "Int", Type: "int", New: 3, Old: 5},
+		{Path: "String", Type: "string", New: "", Old: ""},
+		{Path: "Slice.0.Int", Type: "int", New: 0, Old: 4},
+		{Path: "Int", Type: "int", New: 1, Old: 3},
+	}, inv, "invert")
+
+	_, err = rift.Apply(give, inv.Nodes())
+	assertEqual(t, nil, err, "apply inverted")
+	assertEqual(t, &TestData{Int: 1, Slice: []TestData{{}, {Int: 2}}}, give, "undone")
+}
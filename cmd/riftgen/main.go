@@ -0,0 +1,464 @@
+// Command riftgen generates reflection-free path accessors for Go types.
+//
+// It reads the Go files of a package and, for every struct type annotated
+// with a //rift:generate comment, emits GetPath, SetPath and Get methods
+// implementing [rift.Pathable], plus path constants for its fields and a
+// path type to build compile-time checked paths, such as
+//
+//	UserPaths.Addresses().At(0).Street() // "Addresses.0.Street"
+//
+// The fields promoted from embedded structs are accessed through them,
+// and nil values set the zero value, as reflection does.
+//
+// Usage:
+//
+//	//go:generate go run github.com/ofabricio/rift/cmd/riftgen
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+const annotation = "//rift:generate"
+
+func main() {
+	dir := flag.String("dir", ".", "package directory")
+	out := flag.String("output", "rift_gen.go", "output file name, relative to dir")
+	flag.Parse()
+
+	src, err := Generate(*dir, *out)
+	if err != nil {
+		log.Fatal("riftgen: ", err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0o644); err != nil {
+		log.Fatal("riftgen: ", err)
+	}
+}
+
+// Generate returns the generated source for the package in dir.
+// The file named skip is not read.
+func Generate(dir, skip string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return fi.Name() != skip && !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	var pkg file
+	structs := map[string]*ast.StructType{}
+	for _, p := range pkgs {
+		pkg.Package = p.Name
+		names := make([]string, 0, len(p.Files))
+		for name := range p.Files {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			pkg.Types = append(pkg.Types, collect(p.Files[name], structs)...)
+		}
+	}
+	known := map[string]bool{}
+	for _, t := range pkg.Types {
+		known[t.Name] = true
+	}
+	for _, t := range pkg.Types {
+		promote(t.Fields, structs)
+		for i, f := range t.Fields {
+			pkg.Strconv = pkg.Strconv || f.Indexed
+			pkg.Errors = pkg.Errors || f.Foreign
+			t.Fields[i].Path = pathType(f.Expr, known)
+		}
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, pkg); err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+// collect returns the annotated struct types of a file,
+// and adds all its struct types to structs.
+func collect(f *ast.File, structs map[string]*ast.StructType) []typ {
+	var out []typ
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gd.Specs {
+			ts := s.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || ts.TypeParams != nil {
+				continue
+			}
+			structs[ts.Name.Name] = st
+			if !(annotated(gd.Doc) || annotated(ts.Doc)) {
+				continue
+			}
+			out = append(out, typ{Name: ts.Name.Name, Fields: fields(st)})
+		}
+	}
+	return out
+}
+
+func annotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the exported fields of a struct.
+func fields(st *ast.StructType) []field {
+	var out []field
+	for _, f := range st.Fields.List {
+		typ := types.ExprString(f.Type)
+		names := f.Names
+		embedded := names == nil
+		if embedded {
+			names = []*ast.Ident{embeddedName(f.Type)}
+		}
+		for _, n := range names {
+			if n == nil || !n.IsExported() {
+				continue
+			}
+			_, indexed := f.Type.(*ast.ArrayType)
+			out = append(out, field{
+				Name:     n.Name,
+				Type:     typ,
+				Expr:     f.Type,
+				Kind:     basicKinds[typ],
+				Indexed:  indexed,
+				Embedded: embedded,
+			})
+		}
+	}
+	return out
+}
+
+// promote sets the fields promoted from the embedded structs of fs,
+// as Go does: shallower fields shadow deeper ones, and fields at the
+// same depth in two embedded structs are left out.
+// Embedded structs declared in other packages are marked Foreign.
+func promote(fs []field, structs map[string]*ast.StructType) {
+	type owner struct {
+		field, depth int
+		ambiguous    bool
+	}
+	found := map[string]owner{}
+	for _, f := range fs {
+		found[f.Name] = owner{field: -1}
+	}
+	names := make([][]string, len(fs))
+	for i, f := range fs {
+		if !f.Embedded {
+			continue
+		}
+		st := embeddedStruct(f.Expr, structs)
+		if st == nil {
+			fs[i].Foreign = foreign(f.Expr)
+			continue
+		}
+		walkFields(st, structs, 1, nil, func(name string, depth int) {
+			names[i] = append(names[i], name)
+			switch o, ok := found[name]; {
+			case !ok || depth < o.depth:
+				found[name] = owner{field: i, depth: depth}
+			case depth == o.depth:
+				o.ambiguous = true
+				found[name] = o
+			}
+		})
+	}
+	for i := range fs {
+		for _, n := range names[i] {
+			if o := found[n]; o.field == i && !o.ambiguous && !slices.Contains(fs[i].Promoted, n) {
+				fs[i].Promoted = append(fs[i].Promoted, n)
+			}
+		}
+	}
+}
+
+// walkFields calls fn with the name and depth of the exported fields of st,
+// and of the structs it embeds, one level deeper.
+func walkFields(st *ast.StructType, structs map[string]*ast.StructType, depth int, seen []*ast.StructType, fn func(name string, depth int)) {
+	if slices.Contains(seen, st) {
+		return
+	}
+	seen = append(seen, st)
+	for _, f := range fields(st) {
+		fn(f.Name, depth)
+		if e := embeddedStruct(f.Expr, structs); f.Embedded && e != nil {
+			walkFields(e, structs, depth+1, seen, fn)
+		}
+	}
+}
+
+// embeddedStruct returns the struct of an embedded type declared in the package.
+func embeddedStruct(e ast.Expr, structs map[string]*ast.StructType) *ast.StructType {
+	if s, ok := e.(*ast.StarExpr); ok {
+		e = s.X
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		return structs[id.Name]
+	}
+	return nil
+}
+
+// foreign reports whether e is a type of another package.
+func foreign(e ast.Expr) bool {
+	if s, ok := e.(*ast.StarExpr); ok {
+		e = s.X
+	}
+	_, ok := e.(*ast.SelectorExpr)
+	return ok
+}
+
+// pathType returns the type of the path of a field of type e.
+// Types not annotated have plain string paths.
+func pathType(e ast.Expr, known map[string]bool) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if known[t.Name] {
+			return t.Name + "Path"
+		}
+	case *ast.StarExpr:
+		return pathType(t.X, known)
+	case *ast.ArrayType:
+		return "rift.SlicePath[" + pathType(t.Elt, known) + "]"
+	case *ast.MapType:
+		if k, ok := t.Key.(*ast.Ident); ok && k.Name == "string" {
+			return "rift.MapPath[" + pathType(t.Value, known) + "]"
+		}
+	}
+	return "string"
+}
+
+func embeddedName(e ast.Expr) *ast.Ident {
+	switch t := e.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	}
+	return nil
+}
+
+// basicKinds maps the predeclared types accessed directly to their kind.
+var basicKinds = map[string]string{
+	"bool":       "bool",
+	"string":     "string",
+	"int":        "int",
+	"int8":       "int8",
+	"int16":      "int16",
+	"int32":      "int32",
+	"int64":      "int64",
+	"uint":       "uint",
+	"uint8":      "uint8",
+	"uint16":     "uint16",
+	"uint32":     "uint32",
+	"uint64":     "uint64",
+	"uintptr":    "uintptr",
+	"float32":    "float32",
+	"float64":    "float64",
+	"complex64":  "complex64",
+	"complex128": "complex128",
+	"byte":       "uint8",
+	"rune":       "int32",
+}
+
+type file struct {
+	Package string
+	Strconv bool
+	Errors  bool
+	Types   []typ
+}
+
+type typ struct {
+	Name   string
+	Fields []field
+}
+
+// Foreign reports whether t embeds a struct of another package.
+func (t typ) Foreign() bool {
+	return slices.ContainsFunc(t.Fields, func(f field) bool { return f.Foreign })
+}
+
+type field struct {
+	Name     string
+	Type     string
+	Expr     ast.Expr
+	Path     string   // Type of the path of the field.
+	Kind     string   // Kind of a basic type; empty for types accessed by reflection.
+	Indexed  bool     // Slice or array.
+	Embedded bool     // Embedded field.
+	Promoted []string // Fields promoted from an embedded struct of the package.
+	Foreign  bool     // Embedded struct of another package, whose fields are not known.
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by riftgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	{{- if .Errors}}
+	"errors"
+	{{- end}}
+	{{- if .Strconv}}
+	"strconv"
+	{{- end}}
+	"strings"
+
+	"github.com/ofabricio/rift"
+)
+{{range $t := .Types}}
+// Paths of {{$t.Name}}.
+const (
+{{- range .Fields}}
+	{{$t.Name}}Path{{.Name}} = "{{.Name}}"
+{{- end}}
+)
+{{range .Fields}}{{if .Indexed}}
+// {{$t.Name}}Path{{.Name}}At returns the path of the element i of {{.Name}}.
+func {{$t.Name}}Path{{.Name}}At(i int) string {
+	return "{{.Name}}." + strconv.Itoa(i)
+}
+{{end}}{{end}}
+// {{$t.Name}}Path is the path of a {{$t.Name}}.
+type {{$t.Name}}Path string
+
+// {{$t.Name}}Paths is the root path of {{$t.Name}}.
+const {{$t.Name}}Paths {{$t.Name}}Path = ""
+{{range .Fields}}
+// {{.Name}} returns the path of {{.Name}}.
+func (p {{$t.Name}}Path) {{.Name}}() {{.Path}} {
+	{{- if eq .Path "string"}}
+	return rift.JoinPath(string(p), "{{.Name}}")
+	{{- else}}
+	return {{.Path}}(rift.JoinPath(string(p), "{{.Name}}"))
+	{{- end}}
+}
+{{end}}
+// Get returns the tree of x.
+func (x *{{$t.Name}}) Get() rift.Node {
+	return rift.Node{Type: "struct", Next: []rift.Node{
+	{{- range .Fields}}
+		{{- if .Kind}}
+		{Name: "{{.Name}}", Path: "{{.Name}}", Type: "{{.Kind}}", Data: x.{{.Name}}},
+		{{- else}}
+		rift.Get(x.{{.Name}}).Rebase("{{.Name}}"),
+		{{- end}}
+	{{- end}}
+	}}
+}
+
+// GetPath returns the value at path.
+func (x *{{$t.Name}}) GetPath(path string) (any, error) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "":
+		return *x, nil
+	{{- range .Fields}}
+	case "{{.Name}}":
+		{{- if .Kind}}
+		if rest == "" {
+			return x.{{.Name}}, nil
+		}
+		{{- else}}
+		return rift.GetPath(x.{{.Name}}, rest)
+		{{- end}}
+	{{- end}}
+	{{- range .Fields}}{{if .Promoted}}
+	case {{range $i, $n := .Promoted}}{{if $i}}, {{end}}"{{$n}}"{{end}}:
+		return rift.GetPath(x.{{.Name}}, path)
+	{{- end}}{{end}}
+	{{- if $t.Foreign}}
+	default:
+		{{- range .Fields}}{{if .Foreign}}
+		if v, err := rift.GetPath(x.{{.Name}}, path); !errors.Is(err, rift.ErrNotFound) {
+			return v, err
+		}
+		{{- end}}{{end}}
+	{{- end}}
+	}
+	return nil, rift.ErrNotFound
+}
+
+// SetPath sets val at path and returns the old value.
+func (x *{{$t.Name}}) SetPath(path string, val any) (any, error) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "":
+		var v {{$t.Name}}
+		if val != nil {
+			var ok bool
+			if v, ok = val.({{$t.Name}}); !ok {
+				return nil, rift.ErrType
+			}
+		}
+		old := *x
+		*x = v
+		return old, nil
+	{{- range .Fields}}
+	case "{{.Name}}":
+		{{- if .Kind}}
+		if rest == "" {
+			var v {{.Type}}
+			if val != nil {
+				var ok bool
+				if v, ok = val.({{.Type}}); !ok {
+					return nil, rift.ErrType
+				}
+			}
+			old := x.{{.Name}}
+			x.{{.Name}} = v
+			return old, nil
+		}
+		{{- else}}
+		c, err := rift.TrySetPath(&x.{{.Name}}, rest, val)
+		return c.Old, err
+		{{- end}}
+	{{- end}}
+	{{- range .Fields}}{{if .Promoted}}
+	case {{range $i, $n := .Promoted}}{{if $i}}, {{end}}"{{$n}}"{{end}}:
+		c, err := rift.TrySetPath(&x.{{.Name}}, path, val)
+		return c.Old, err
+	{{- end}}{{end}}
+	{{- if $t.Foreign}}
+	default:
+		{{- range .Fields}}{{if .Foreign}}
+		if c, err := rift.TrySetPath(&x.{{.Name}}, path, val); !errors.Is(err, rift.ErrNotFound) {
+			return c.Old, err
+		}
+		{{- end}}{{end}}
+	{{- end}}
+	}
+	return nil, rift.ErrNotFound
+}
+
+var _ rift.Pathable = (*{{$t.Name}})(nil)
+{{end}}`))
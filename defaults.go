@@ -0,0 +1,149 @@
+package rift
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Defaulter is implemented by types that set their own defaults.
+// SetDefaults is called after the default tags of the type are applied.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// ApplyDefaults sets the zero fields of dst that have a default tag,
+// as in `default:"10"`, and calls the SetDefaults method of the values
+// implementing [Defaulter], throughout dst, and returns the changes.
+// Nil pointers with a default tag are allocated to hold their default,
+// and other nil pointers are left nil, as are the structs they point to.
+//
+// Defaults are also applied to the structs that [Set] and the other
+// functions allocate, such as new slice elements.
+//
+// Strings are taken as they are, time.Duration values are parsed by
+// [time.ParseDuration], and the other types are decoded from JSON.
+// It panics with a [*PathError] if a default cannot be decoded.
+func ApplyDefaults(dst any) []Change {
+	var chgs []Change
+	if err := setDefaults(reflect.ValueOf(dst), "", &chgs); err != nil {
+		panic(err)
+	}
+	return chgs
+}
+
+// setDefaults applies the defaults throughout v, recording the changes in chgs, if not nil.
+func setDefaults(v reflect.Value, path string, chgs *[]Change) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return setDefaults(v.Elem(), path, chgs)
+		}
+	case reflect.Interface:
+		// Values held by interfaces are not addressable.
+		if e := v.Elem(); e.IsValid() && v.CanSet() {
+			c := reflect.New(e.Type()).Elem()
+			c.Set(e)
+			if err := setDefaults(c, path, chgs); err != nil {
+				return err
+			}
+			v.Set(c)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := setDefaults(v.Index(i), joinPath(path, strconv.Itoa(i)), chgs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			// Map values are not addressable.
+			c := reflect.New(v.Type().Elem()).Elem()
+			c.Set(it.Value())
+			if err := setDefaults(c, joinPath(path, it.Key().String()), chgs); err != nil {
+				return err
+			}
+			v.SetMapIndex(it.Key(), c)
+		}
+	case reflect.Struct:
+		if !v.CanSet() {
+			return nil
+		}
+		for i := range v.NumField() {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			p := joinPath(path, f.Name)
+			fv := v.Field(i)
+			if raw, ok := f.Tag.Lookup("default"); ok && fv.IsZero() {
+				d, err := parseDefault(f.Type, raw)
+				if err != nil {
+					return &PathError{Path: p, Err: err}
+				}
+				old := valueOf(derefValue(fv))
+				fv.Set(d)
+				if chgs != nil {
+					*chgs = append(*chgs, Change{Path: p, Type: getType(derefValue(d)), New: valueOf(derefValue(d)), Old: old})
+				}
+			}
+			if err := setDefaults(fv, p, chgs); err != nil {
+				return err
+			}
+		}
+		if d, ok := v.Addr().Interface().(Defaulter); ok {
+			if chgs == nil {
+				d.SetDefaults()
+				return nil
+			}
+			old := GetFlat(d)
+			d.SetDefaults()
+			*chgs = append(*chgs, diffFlat(path, old, GetFlat(d))...)
+		}
+	}
+	return nil
+}
+
+// parseDefault returns the default value raw as a value of type t.
+func parseDefault(t reflect.Type, raw string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch {
+	case t.Kind() == reflect.Pointer:
+		e, err := parseDefault(t.Elem(), raw)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(e)
+	case t == reflect.TypeFor[time.Duration]():
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return v, fmt.Errorf("%w: default %q", ErrType, raw)
+		}
+		v.SetInt(int64(d))
+	case t.Kind() == reflect.String:
+		v.SetString(raw)
+	default:
+		if err := json.Unmarshal([]byte(raw), v.Addr().Interface()); err != nil {
+			return v, fmt.Errorf("%w: default %q", ErrType, raw)
+		}
+	}
+	return v, nil
+}
+
+// diffFlat returns the changes from the flat nodes old to new, relative to path.
+func diffFlat(path string, old, new []Node) []Change {
+	prev := map[string]any{}
+	for _, n := range old {
+		prev[n.Path] = n.Data
+	}
+	var out []Change
+	for _, n := range new {
+		if o, ok := prev[n.Path]; !ok || !reflect.DeepEqual(o, n.Data) {
+			out = append(out, Change{Path: joinPath(path, n.Path), Type: n.Type, New: n.Data, Old: o})
+		}
+	}
+	return out
+}
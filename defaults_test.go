@@ -0,0 +1,71 @@
+package rift_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ofabricio/rift"
+)
+
+type Shipping struct {
+	Street  string
+	Country string `default:"BR"`
+	Tries   int    `default:"3"`
+	Label   string
+}
+
+func (s *Shipping) SetDefaults() {
+	if s.Label == "" {
+		s.Label = s.Country + "-" + s.Street
+	}
+}
+
+func TestDefaults(t *testing.T) {
+
+	type Settings struct {
+		Timeout   time.Duration `default:"5s"`
+		Retries   *int          `default:"2"`
+		Tags      []string      `default:"[\"a\",\"b\"]"`
+		Addresses []Shipping
+		Primary   *Shipping
+		ByName    map[string]Shipping
+	}
+
+	var s Settings
+	rift.SetMany(&s,
+		rift.Path("Addresses.1.Street", "Main"),
+		rift.Path("Primary.Tries", 5),
+		rift.Path("ByName.home.Street", "Home"),
+	)
+	assertEqual(t, Settings{
+		Addresses: []Shipping{
+			{Country: "BR", Tries: 3, Label: "BR-"},
+			{Street: "Main", Country: "BR", Tries: 3, Label: "BR-"},
+		},
+		Primary: &Shipping{Country: "BR", Tries: 5, Label: "BR-"},
+		ByName:  map[string]Shipping{"home": {Street: "Home", Country: "BR", Tries: 3, Label: "BR-"}},
+	}, s, "allocated")
+
+	var h TestData
+	h.Any = []Shipping{}
+	rift.SetPath(&h, "Any.1.Street", "Main")
+	assertEqual(t, []Shipping{
+		{Country: "BR", Tries: 3, Label: "BR-"},
+		{Street: "Main", Country: "BR", Tries: 3, Label: "BR-"},
+	}, h.Any, "allocated in an interface")
+
+	give := Settings{Tags: []string{"x"}, Addresses: []Shipping{{Street: "Main", Tries: 1}}}
+	cs := rift.ApplyDefaults(&give)
+	assertEqual(t, []rift.Change{
+		{Path: "Timeout", Type: "Duration", New: 5 * time.Second, Old: time.Duration(0)},
+		{Path: "Retries", Type: "int", New: 2, Old: nil},
+		{Path: "Addresses.0.Country", Type: "string", New: "BR", Old: ""},
+		{Path: "Addresses.0.Label", Type: "string", New: "BR-Main", Old: ""},
+	}, cs, "apply defaults")
+	assertEqual(t, Settings{
+		Timeout:   5 * time.Second,
+		Retries:   ptr(2),
+		Tags:      []string{"x"},
+		Addresses: []Shipping{{Street: "Main", Country: "BR", Tries: 1, Label: "BR-Main"}},
+	}, give, "apply defaults")
+}
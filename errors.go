@@ -0,0 +1,35 @@
+package rift
+
+import "errors"
+
+var (
+	ErrNotFound    = errors.New("path not found")
+	ErrIndex       = errors.New("invalid index")
+	ErrType        = errors.New("type mismatch")
+	ErrNotSettable = errors.New("value not settable")
+	ErrConflict    = errors.New("value conflict")
+)
+
+// PathError records an error and the path that caused it.
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return "rift: " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// pathError wraps err with path.
+// A nested [*PathError] is replaced since its path is relative.
+func pathError(path string, err error) error {
+	var pe *PathError
+	if errors.As(err, &pe) {
+		err = pe.Err
+	}
+	return &PathError{Path: path, Err: err}
+}
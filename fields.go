@@ -0,0 +1,176 @@
+package rift
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FlattenEmbedded promotes the fields of embedded structs to the struct
+// embedding them, as encoding/json does, so that the fields of an embedded
+// Location are read, written and reported as "City" instead of
+// "Location.City". Embedded structs named by a rift tag are not flattened.
+//
+// Without it, embedded structs are nested under their type name.
+// Either way, both paths can be set, and changes report the path
+// of the mode in use.
+func FlattenEmbedded() Option {
+	return func(c *config) {
+		c.flatten = true
+	}
+}
+
+// structFields returns the exported fields of struct type t.
+// The fields of flattened embedded structs are listed in their place,
+// except those shadowed by, or conflicting with, another field.
+func (c *config) structFields(t reflect.Type) []reflect.StructField {
+	var out []reflect.StructField
+	if !c.flatten {
+		for i := range t.NumField() {
+			if f := t.Field(i); f.IsExported() {
+				out = append(out, f)
+			}
+		}
+		return out
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || c.flattened(f) || !c.promoted(t, f) {
+			continue
+		}
+		if g, ok := t.FieldByName(f.Name); !ok || !slices.Equal(g.Index, f.Index) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// promoted reports whether every struct embedding f in t is flattened.
+func (c *config) promoted(t reflect.Type, f reflect.StructField) bool {
+	for i := 1; i < len(f.Index); i++ {
+		if !c.flattened(t.FieldByIndex(f.Index[:i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreCase matches the fields by name regardless of case,
+// so that "name" and "NAME" set the field Name.
+func IgnoreCase() Option {
+	return func(c *config) {
+		c.match = matchFold
+	}
+}
+
+// NormalizeCase is like [IgnoreCase], but also ignores underscores
+// and dashes, so that "street_name" sets the field StreetName.
+func NormalizeCase() Option {
+	return func(c *config) {
+		c.match = matchNormal
+	}
+}
+
+type matchMode int
+
+const (
+	matchExact matchMode = iota
+	matchFold
+	matchNormal
+)
+
+// fieldByName returns the exported field of struct type t named name,
+// which may be promoted from an embedded struct.
+// Fields are matched by their Go name, then by the name and aliases of
+// their rift tag, as in `rift:"street,alias=street_name"`, and then
+// by any of them with the case matching of c.
+func (c *config) fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	if f, ok := t.FieldByName(name); ok && f.IsExported() {
+		return f, true
+	}
+	var fields []reflect.StructField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		if g, ok := t.FieldByName(f.Name); ok && slices.Equal(g.Index, f.Index) {
+			fields = append(fields, f)
+		}
+	}
+	if f, ok := shallowest(fields, func(f reflect.StructField) bool {
+		return slices.Contains(fieldNames(f)[1:], name)
+	}); ok || c.match == matchExact {
+		return f, ok
+	}
+	key := c.matchKey(name)
+	return shallowest(fields, func(f reflect.StructField) bool {
+		return slices.ContainsFunc(fieldNames(f), func(n string) bool {
+			return c.matchKey(n) == key
+		})
+	})
+}
+
+// fieldNames returns the Go name of f followed by the names of its rift tag.
+func fieldNames(f reflect.StructField) []string {
+	t := parseTag(f.Tag)
+	out := []string{f.Name}
+	if t.name != "" {
+		out = append(out, t.name)
+	}
+	return append(out, t.all("alias")...)
+}
+
+// shallowest returns the least nested of the fields matching fn.
+func shallowest(fields []reflect.StructField, fn func(reflect.StructField) bool) (reflect.StructField, bool) {
+	var out reflect.StructField
+	found := false
+	for _, f := range fields {
+		if fn(f) && (!found || len(f.Index) < len(out.Index)) {
+			out, found = f, true
+		}
+	}
+	return out, found
+}
+
+// matchKey returns the form of name compared by the case matching of c.
+func (c *config) matchKey(name string) string {
+	switch c.match {
+	case matchFold:
+		return strings.ToLower(name)
+	case matchNormal:
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	}
+	return name
+}
+
+// fieldPath returns the segments of the path of field f of struct type t.
+// Flattened embedded structs have no segment, unless f is one of them and
+// the path ends at it.
+func (c *config) fieldPath(t reflect.Type, f reflect.StructField, last bool) []string {
+	var out []string
+	for i := range f.Index {
+		sf := t.FieldByIndex(f.Index[:i+1])
+		if (last && i == len(f.Index)-1) || !c.flattened(sf) {
+			out = append(out, sf.Name)
+		}
+	}
+	return out
+}
+
+// flattened reports whether f is an embedded struct whose fields are promoted.
+// Structs without exported fields, such as time.Time, are values on their own.
+func (c *config) flattened(f reflect.StructField) bool {
+	if !c.flatten || !f.Anonymous || parseTag(f.Tag).name != "" {
+		return false
+	}
+	t := derefType(f.Type)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
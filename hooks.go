@@ -0,0 +1,39 @@
+package rift
+
+import "reflect"
+
+// BeforeSetter is implemented by types that check the values set under them.
+// BeforeSetPath is called before setting val at path, relative to the value,
+// and an error aborts the setting.
+// It is called for every struct along the path, from the outermost one.
+type BeforeSetter interface {
+	BeforeSetPath(path string, val any) error
+}
+
+// AfterSetter is implemented by types that react to the values set under them,
+// such as to normalize them or to recompute derived fields.
+// AfterSetPath is called after a value is set, with the change
+// relative to the value.
+// It is called for every struct along the path, from the innermost one.
+type AfterSetter interface {
+	AfterSetPath(Change)
+}
+
+// hooks returns the struct v, or a pointer to it, to check for hooks.
+func hooks(v reflect.Value) any {
+	if !v.CanInterface() {
+		return nil
+	}
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
+
+// valueOf returns the value held by v, or nil.
+func valueOf(v reflect.Value) any {
+	if v.IsValid() {
+		return v.Interface()
+	}
+	return nil
+}
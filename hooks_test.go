@@ -0,0 +1,77 @@
+package rift_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+type Order struct {
+	Customer string
+	Items    []Item
+	Total    int
+}
+
+// hookCalls records the calls of the hooks.
+var hookCalls []string
+
+func (o *Order) BeforeSetPath(path string, val any) error {
+	hookCalls = append(hookCalls, "order before "+path)
+	if path == "Total" {
+		return errors.New("total is computed")
+	}
+	return nil
+}
+
+func (o *Order) AfterSetPath(c rift.Change) {
+	hookCalls = append(hookCalls, "order after "+c.Path)
+	o.Customer = strings.TrimSpace(o.Customer)
+	o.Total = 0
+	for _, v := range o.Items {
+		o.Total += v.Price
+	}
+}
+
+type Item struct {
+	Price int
+}
+
+func (i *Item) BeforeSetPath(path string, val any) error {
+	if v, ok := val.(int); ok && v < 0 {
+		return errors.New("negative price")
+	}
+	hookCalls = append(hookCalls, "item before "+path)
+	return nil
+}
+
+func (i *Item) AfterSetPath(c rift.Change) {
+	hookCalls = append(hookCalls, "item after "+c.Path)
+}
+
+func TestHooks(t *testing.T) {
+
+	var o Order
+	hookCalls = nil
+
+	c := rift.SetPath(&o, "Items.0.Price", 10)
+	assertEqual(t, rift.Change{Path: "Items.0.Price", Type: "int", New: 10, Old: 0}, c, "nested")
+	assertEqual(t, 10, o.Total, "recomputed")
+	assertEqual(t, []string{
+		"order before Items.0.Price",
+		"item before Price",
+		"item after Price",
+		"order after Items.0.Price",
+	}, hookCalls, "order of calls")
+
+	rift.SetPath(&o, "Customer", "  John ")
+	assertEqual(t, "John", o.Customer, "normalized")
+
+	_, err := rift.TrySetPath(&o, "Total", 5)
+	assertEqual(t, "rift: Total: total is computed", err.Error(), "aborted")
+	assertEqual(t, 10, o.Total, "aborted")
+
+	_, err = rift.TrySetPath(&o, "Items.1.Price", -1)
+	assertEqual(t, "rift: Items.1.Price: negative price", err.Error(), "aborted inner")
+}
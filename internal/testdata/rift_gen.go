@@ -0,0 +1,251 @@
+// Code generated by riftgen. DO NOT EDIT.
+
+package testdata
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ofabricio/rift"
+)
+
+// Paths of User.
+const (
+	UserPathName      = "Name"
+	UserPathAge       = "Age"
+	UserPathTags      = "Tags"
+	UserPathAddresses = "Addresses"
+	UserPathLocation  = "Location"
+)
+
+// UserPathTagsAt returns the path of the element i of Tags.
+func UserPathTagsAt(i int) string {
+	return "Tags." + strconv.Itoa(i)
+}
+
+// UserPathAddressesAt returns the path of the element i of Addresses.
+func UserPathAddressesAt(i int) string {
+	return "Addresses." + strconv.Itoa(i)
+}
+
+// UserPath is the path of a User.
+type UserPath string
+
+// UserPaths is the root path of User.
+const UserPaths UserPath = ""
+
+// Name returns the path of Name.
+func (p UserPath) Name() string {
+	return rift.JoinPath(string(p), "Name")
+}
+
+// Age returns the path of Age.
+func (p UserPath) Age() string {
+	return rift.JoinPath(string(p), "Age")
+}
+
+// Tags returns the path of Tags.
+func (p UserPath) Tags() rift.SlicePath[string] {
+	return rift.SlicePath[string](rift.JoinPath(string(p), "Tags"))
+}
+
+// Addresses returns the path of Addresses.
+func (p UserPath) Addresses() rift.SlicePath[AddressPath] {
+	return rift.SlicePath[AddressPath](rift.JoinPath(string(p), "Addresses"))
+}
+
+// Location returns the path of Location.
+func (p UserPath) Location() string {
+	return rift.JoinPath(string(p), "Location")
+}
+
+// Get returns the tree of x.
+func (x *User) Get() rift.Node {
+	return rift.Node{Type: "struct", Next: []rift.Node{
+		{Name: "Name", Path: "Name", Type: "string", Data: x.Name},
+		{Name: "Age", Path: "Age", Type: "int", Data: x.Age},
+		rift.Get(x.Tags).Rebase("Tags"),
+		rift.Get(x.Addresses).Rebase("Addresses"),
+		rift.Get(x.Location).Rebase("Location"),
+	}}
+}
+
+// GetPath returns the value at path.
+func (x *User) GetPath(path string) (any, error) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "":
+		return *x, nil
+	case "Name":
+		if rest == "" {
+			return x.Name, nil
+		}
+	case "Age":
+		if rest == "" {
+			return x.Age, nil
+		}
+	case "Tags":
+		return rift.GetPath(x.Tags, rest)
+	case "Addresses":
+		return rift.GetPath(x.Addresses, rest)
+	case "Location":
+		return rift.GetPath(x.Location, rest)
+	case "City":
+		return rift.GetPath(x.Location, path)
+	}
+	return nil, rift.ErrNotFound
+}
+
+// SetPath sets val at path and returns the old value.
+func (x *User) SetPath(path string, val any) (any, error) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "":
+		var v User
+		if val != nil {
+			var ok bool
+			if v, ok = val.(User); !ok {
+				return nil, rift.ErrType
+			}
+		}
+		old := *x
+		*x = v
+		return old, nil
+	case "Name":
+		if rest == "" {
+			var v string
+			if val != nil {
+				var ok bool
+				if v, ok = val.(string); !ok {
+					return nil, rift.ErrType
+				}
+			}
+			old := x.Name
+			x.Name = v
+			return old, nil
+		}
+	case "Age":
+		if rest == "" {
+			var v int
+			if val != nil {
+				var ok bool
+				if v, ok = val.(int); !ok {
+					return nil, rift.ErrType
+				}
+			}
+			old := x.Age
+			x.Age = v
+			return old, nil
+		}
+	case "Tags":
+		c, err := rift.TrySetPath(&x.Tags, rest, val)
+		return c.Old, err
+	case "Addresses":
+		c, err := rift.TrySetPath(&x.Addresses, rest, val)
+		return c.Old, err
+	case "Location":
+		c, err := rift.TrySetPath(&x.Location, rest, val)
+		return c.Old, err
+	case "City":
+		c, err := rift.TrySetPath(&x.Location, path, val)
+		return c.Old, err
+	}
+	return nil, rift.ErrNotFound
+}
+
+var _ rift.Pathable = (*User)(nil)
+
+// Paths of Address.
+const (
+	AddressPathStreet = "Street"
+	AddressPathNumber = "Number"
+)
+
+// AddressPath is the path of a Address.
+type AddressPath string
+
+// AddressPaths is the root path of Address.
+const AddressPaths AddressPath = ""
+
+// Street returns the path of Street.
+func (p AddressPath) Street() string {
+	return rift.JoinPath(string(p), "Street")
+}
+
+// Number returns the path of Number.
+func (p AddressPath) Number() string {
+	return rift.JoinPath(string(p), "Number")
+}
+
+// Get returns the tree of x.
+func (x *Address) Get() rift.Node {
+	return rift.Node{Type: "struct", Next: []rift.Node{
+		{Name: "Street", Path: "Street", Type: "string", Data: x.Street},
+		{Name: "Number", Path: "Number", Type: "int", Data: x.Number},
+	}}
+}
+
+// GetPath returns the value at path.
+func (x *Address) GetPath(path string) (any, error) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "":
+		return *x, nil
+	case "Street":
+		if rest == "" {
+			return x.Street, nil
+		}
+	case "Number":
+		if rest == "" {
+			return x.Number, nil
+		}
+	}
+	return nil, rift.ErrNotFound
+}
+
+// SetPath sets val at path and returns the old value.
+func (x *Address) SetPath(path string, val any) (any, error) {
+	head, rest, _ := strings.Cut(path, ".")
+	switch head {
+	case "":
+		var v Address
+		if val != nil {
+			var ok bool
+			if v, ok = val.(Address); !ok {
+				return nil, rift.ErrType
+			}
+		}
+		old := *x
+		*x = v
+		return old, nil
+	case "Street":
+		if rest == "" {
+			var v string
+			if val != nil {
+				var ok bool
+				if v, ok = val.(string); !ok {
+					return nil, rift.ErrType
+				}
+			}
+			old := x.Street
+			x.Street = v
+			return old, nil
+		}
+	case "Number":
+		if rest == "" {
+			var v int
+			if val != nil {
+				var ok bool
+				if v, ok = val.(int); !ok {
+					return nil, rift.ErrType
+				}
+			}
+			old := x.Number
+			x.Number = v
+			return old, nil
+		}
+	}
+	return nil, rift.ErrNotFound
+}
+
+var _ rift.Pathable = (*Address)(nil)
@@ -0,0 +1,29 @@
+// Package testdata holds types used by the tests of rift.
+package testdata
+
+//go:generate go run ../../cmd/riftgen
+
+// User is accessed through generated code.
+//
+//rift:generate
+type User struct {
+	Name      string
+	Age       int
+	Tags      []string
+	Addresses []Address
+	Location
+	secret string
+}
+
+// Address is accessed through generated code.
+//
+//rift:generate
+type Address struct {
+	Street string
+	Number int
+}
+
+// Location is accessed through reflection.
+type Location struct {
+	City string
+}
@@ -0,0 +1,214 @@
+package rift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Plain is a [Node] that marshals to JSON as the plain document it describes,
+// instead of the Name/Path/Type/Data/Next objects of a [Node]:
+//
+//	json.Marshal(rift.Plain(rift.Get(user))) // {"Name":"John","Addresses":[...]}
+//
+// Slices and arrays become arrays, other nodes with children become objects
+// in the order of their children, and nodes without children become their Data.
+type Plain Node
+
+// MarshalJSON implements [json.Marshaler].
+func (p Plain) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := writePlain(&b, Node(p)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writePlain(b *bytes.Buffer, n Node) error {
+	if len(n.Next) == 0 {
+		data, err := json.Marshal(n.Data)
+		b.Write(data)
+		return err
+	}
+	switch n.Type {
+	case reflect.Slice.String(), reflect.Array.String():
+		var items []*Node
+		for i, v := range n.Next {
+			idx, ok := getNumber(nodeName(v))
+			if !ok {
+				return &PathError{Path: v.Path, Err: ErrIndex}
+			}
+			if idx >= len(items) {
+				items = append(items, make([]*Node, idx+1-len(items))...)
+			}
+			items[idx] = &n.Next[i]
+		}
+		b.WriteByte('[')
+		for i, v := range items {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if v == nil {
+				b.WriteString("null")
+			} else if err := writePlain(b, *v); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		b.WriteByte('{')
+		for i, v := range n.Next {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(nodeName(v))
+			b.Write(key)
+			b.WriteByte(':')
+			if err := writePlain(b, v); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	}
+	return nil
+}
+
+// nodeName returns the name of n, or the last segment of its path.
+func nodeName(n Node) string {
+	if n.Name != "" {
+		return n.Name
+	}
+	return n.Path[strings.LastIndexByte(n.Path, '.')+1:]
+}
+
+// NodeFromJSON returns the tree of a plain JSON document,
+// with the values of its leaves decoded into the types
+// of the fields of typeHint they are at, so it can be passed to [Set].
+// Values under interface types are decoded as [json.Unmarshal] does.
+// Empty objects and arrays are leaves decoded as such,
+// and null is a leaf with nil Data, which [Set] sets as the zero value.
+// Object keys are matched to the fields of typeHint as in [SetPath],
+// with the options that apply, and the nodes get the paths of the fields.
+func NodeFromJSON(data []byte, typeHint any, opts ...Option) (Node, error) {
+	c := newConfig(opts)
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var out Node
+	if err := c.fromJSON(d, reflect.TypeOf(typeHint), "", &out); err != nil {
+		return Node{}, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return Node{}, errors.New("rift: invalid data after top-level value")
+	}
+	return out, nil
+}
+
+func (c *config) fromJSON(d *json.Decoder, t reflect.Type, path string, out *Node) error {
+	out.Path = path
+	t = derefType(t)
+	if t != nil && t.Kind() == reflect.Interface {
+		t = nil
+	}
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		if !d.More() {
+			d.Token()
+			return leafFromJSON(t, "{}", out)
+		}
+		out.Type = reflect.Map.String()
+		if t != nil {
+			out.Type = t.Kind().String()
+		}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return err
+			}
+			key := k.(string)
+			p := joinPath(path, key)
+			var kt reflect.Type
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Struct:
+				f, ok := c.fieldByName(t, key)
+				if !ok {
+					return &PathError{Path: p, Err: ErrNotFound}
+				}
+				key = f.Name
+				p = joinPath(path, strings.Join(c.fieldPath(t, f, true), "."))
+				kt = f.Type
+			case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+				kt = t.Elem()
+			default:
+				return &PathError{Path: path, Err: ErrType}
+			}
+			n := Node{Name: key}
+			if err := c.fromJSON(d, kt, p, &n); err != nil {
+				return err
+			}
+			out.Next = append(out.Next, n)
+		}
+		_, err := d.Token()
+		return err
+	case json.Delim('['):
+		if !d.More() {
+			d.Token()
+			return leafFromJSON(t, "[]", out)
+		}
+		out.Type = reflect.Slice.String()
+		var et reflect.Type
+		if t != nil {
+			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return &PathError{Path: path, Err: ErrType}
+			}
+			out.Type = t.Kind().String()
+			et = t.Elem()
+		}
+		for i := 0; d.More(); i++ {
+			name := strconv.Itoa(i)
+			n := Node{Name: name}
+			if err := c.fromJSON(d, et, joinPath(path, name), &n); err != nil {
+				return err
+			}
+			out.Next = append(out.Next, n)
+		}
+		_, err := d.Token()
+		return err
+	default:
+		raw, err := json.Marshal(tok)
+		if err != nil {
+			return err
+		}
+		return leafFromJSON(t, string(raw), out)
+	}
+}
+
+func leafFromJSON(t reflect.Type, raw string, out *Node) error {
+	if t == nil {
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return err
+		}
+		out.Data = v
+		out.Type = getKind(reflect.ValueOf(v))
+		return nil
+	}
+	out.Type = t.Kind().String()
+	if raw == "null" {
+		return nil
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal([]byte(raw), v.Interface()); err != nil {
+		return &PathError{Path: out.Path, Err: ErrType}
+	}
+	out.Data = v.Elem().Interface()
+	return nil
+}
@@ -0,0 +1,80 @@
+package rift_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func ExamplePlain() {
+
+	var user struct {
+		Name      string
+		Addresses []struct {
+			Street string
+			Number int
+		}
+	}
+
+	rift.SetPath(&user, "Name", "John")
+	rift.SetPath(&user, "Addresses.0.Street", "Main")
+	rift.SetPath(&user, "Addresses.0.Number", 100)
+
+	data, _ := json.Marshal(rift.Plain(rift.Get(user)))
+
+	fmt.Println(string(data))
+
+	// Output:
+	// {"Name":"John","Addresses":[{"Street":"Main","Number":100}]}
+}
+
+func TestNodeFromJSON(t *testing.T) {
+
+	give := TestData{
+		Int:      11,
+		IntPtr:   ptr(22),
+		String:   "Hello",
+		Slice:    []TestData{{Int: 33}},
+		SlicePtr: []*TestData{{Int: 44}},
+		Struct:   &TestData{Int: 55},
+		Any:      map[string]any{"Int": 66.0},
+		Map:      map[string]any{"Arr": []any{77.0, "a"}},
+	}
+
+	data, err := json.Marshal(rift.Plain(rift.Get(give)))
+	assertEqual(t, nil, err, "marshal")
+
+	n, err := rift.NodeFromJSON(data, TestData{})
+	assertEqual(t, nil, err, "unmarshal")
+
+	var then TestData
+	rift.Set(&then, n)
+	assertEqual(t, give, then, "round trip")
+
+	n, err = rift.NodeFromJSON([]byte(`{"Int": 3, "Slice": [{"IntPtr": 4}], "Any": {"a": 5}, "Map": {}}`), &TestData{})
+	assertEqual(t, nil, err, "typed leaves")
+	assertEqual(t, rift.Node{Type: "struct", Next: []rift.Node{
+		{Name: "Int", Path: "Int", Type: "int", Data: 3},
+		{Name: "Slice", Path: "Slice", Type: "slice", Next: []rift.Node{
+			{Name: "0", Path: "Slice.0", Type: "struct", Next: []rift.Node{
+				{Name: "IntPtr", Path: "Slice.0.IntPtr", Type: "int", Data: 4},
+			}},
+		}},
+		{Name: "Any", Path: "Any", Type: "map", Next: []rift.Node{
+			{Name: "a", Path: "Any.a", Type: "float64", Data: 5.0},
+		}},
+		{Name: "Map", Path: "Map", Type: "map", Data: map[string]any{}},
+	}}, n, "typed leaves")
+
+	_, err = rift.NodeFromJSON([]byte(`{"Int": "3"}`), TestData{})
+	assertEqual(t, "rift: Int: type mismatch", fmt.Sprint(err), "type mismatch")
+
+	_, err = rift.NodeFromJSON([]byte(`{"Nope": 3}`), TestData{})
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "unknown field")
+
+	_, err = rift.NodeFromJSON([]byte(`{"Int": 3} {}`), TestData{})
+	assertEqual(t, true, err != nil, "trailing data")
+}
@@ -0,0 +1,90 @@
+package rift
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the dialect of the documents returned by [JSONSchema].
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema document describing the paths
+// accepted by the provided type, ready to be marshalled.
+// Named struct types are placed in $defs.
+//
+// Fields tagged `rift:",required"` are listed as required and
+// fields tagged `rift:",readonly"` are marked as read-only.
+func JSONSchema(t reflect.Type, opts ...Option) map[string]any {
+	defs := map[string]any{}
+	root := jsonSchema(Schema(t, opts...), defs)
+	root["$schema"] = JSONSchemaDraft
+	if len(defs) > 0 {
+		root["$defs"] = defs
+	}
+	return root
+}
+
+func jsonSchema(n Node, defs map[string]any) map[string]any {
+	goType := strings.TrimLeft(n.GoType, "*")
+	out := map[string]any{}
+	switch n.Type {
+	case "bool":
+		out["type"] = "boolean"
+	case "string":
+		out["type"] = "string"
+	case "int", "int8", "int16", "int32", "int64":
+		out["type"] = "integer"
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		out["type"] = "integer"
+		out["minimum"] = 0
+	case "float32", "float64":
+		out["type"] = "number"
+	case "slice", "array":
+		out["type"] = "array"
+		out["items"] = jsonSchema(n.Next[0], defs)
+	case "map":
+		out["type"] = "object"
+		if n.Next != nil {
+			out["additionalProperties"] = jsonSchema(n.Next[0], defs)
+		}
+	case "struct":
+		if goType == "time.Time" {
+			out["type"] = "string"
+			out["format"] = "date-time"
+			break
+		}
+		if strings.HasPrefix(goType, "struct {") {
+			out = jsonObject(n, defs)
+			break
+		}
+		if _, ok := defs[goType]; !ok {
+			defs[goType] = map[string]any{}
+			defs[goType] = jsonObject(n, defs)
+		}
+		out["$ref"] = "#/$defs/" + goType
+	}
+	if t := parseTag(n.Tag); t.has("readonly") {
+		out["readOnly"] = true
+	}
+	return out
+}
+
+func jsonObject(n Node, defs map[string]any) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for _, v := range n.Next {
+		props[v.Name] = jsonSchema(v, defs)
+		if parseTag(v.Tag).has("required") {
+			required = append(required, v.Name)
+		}
+	}
+	out := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if required != nil {
+		out["required"] = required
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package rift_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestJSONSchema(t *testing.T) {
+
+	type Address struct {
+		Street string `rift:",required"`
+		Number uint
+	}
+
+	type User struct {
+		ID        int `rift:",readonly"`
+		Name      string
+		Score     float64
+		Addresses []Address
+		Meta      map[string]any
+		Parent    *User
+		Created   time.Time
+	}
+
+	exp := `{
+	"$defs": {
+		"rift_test.Address": {
+			"additionalProperties": false,
+			"properties": {
+				"Number": {"minimum": 0, "type": "integer"},
+				"Street": {"type": "string"}
+			},
+			"required": ["Street"],
+			"type": "object"
+		},
+		"rift_test.User": {
+			"additionalProperties": false,
+			"properties": {
+				"Addresses": {"items": {"$ref": "#/$defs/rift_test.Address"}, "type": "array"},
+				"Created": {"format": "date-time", "type": "string"},
+				"ID": {"readOnly": true, "type": "integer"},
+				"Meta": {"additionalProperties": {}, "type": "object"},
+				"Name": {"type": "string"},
+				"Parent": {"$ref": "#/$defs/rift_test.User"},
+				"Score": {"type": "number"}
+			},
+			"type": "object"
+		}
+	},
+	"$ref": "#/$defs/rift_test.User",
+	"$schema": "https://json-schema.org/draft/2020-12/schema"
+}`
+
+	var expv, gotv any
+	data, err := json.Marshal(rift.JSONSchema(reflect.TypeFor[User]()))
+	assertEqual(t, nil, err, "marshal")
+	assertEqual(t, nil, json.Unmarshal([]byte(exp), &expv), "unmarshal expected")
+	assertEqual(t, nil, json.Unmarshal(data, &gotv), "unmarshal")
+	assertEqual(t, expv, gotv, string(data))
+}
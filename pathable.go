@@ -0,0 +1,51 @@
+package rift
+
+import "reflect"
+
+// Pathable is implemented by types that access their own paths
+// without reflection, usually generated by riftgen.
+// [Get], [GetPath], [SetPath] and the functions built
+// on them dispatch to it when they reach such a type.
+//
+// Its methods take no options, so such a type is accessed by reflection
+// when options changing how paths are resolved or reported are used,
+// such as [FullTypes], [Structural], [Atomic], [FlattenEmbedded],
+// [IgnoreCase], [Accessors] and [Redact].
+type Pathable interface {
+	// Get returns the tree of the value, with paths relative to it.
+	Get() Node
+	// GetPath returns the value at path.
+	GetPath(path string) (any, error)
+	// SetPath sets val at path and returns the old value.
+	SetPath(path string, val any) (old any, err error)
+}
+
+var pathableType = reflect.TypeFor[Pathable]()
+
+// pathable reports whether the Pathable types are dispatched to.
+func (c *config) pathable() bool {
+	return !c.fullTypes && !c.structural && !c.undo && !c.flatten && c.match == matchExact && !c.accessors && !c.redact
+}
+
+// asPathable returns v as a Pathable for reading.
+// A non-addressable v is copied.
+func (c *config) asPathable(v reflect.Value) (Pathable, bool) {
+	if !c.pathable() || v.Kind() != reflect.Struct || !v.CanInterface() || !reflect.PointerTo(v.Type()).Implements(pathableType) {
+		return nil, false
+	}
+	if !v.CanAddr() {
+		c := reflect.New(v.Type())
+		c.Elem().Set(v)
+		return c.Interface().(Pathable), true
+	}
+	return v.Addr().Interface().(Pathable), true
+}
+
+// addrPathable returns v as a Pathable for writing.
+func (c *config) addrPathable(v reflect.Value) (Pathable, bool) {
+	if !c.pathable() || !v.CanAddr() || !v.CanInterface() {
+		return nil, false
+	}
+	p, ok := v.Addr().Interface().(Pathable)
+	return p, ok
+}
@@ -0,0 +1,146 @@
+package rift_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ofabricio/rift"
+	"github.com/ofabricio/rift/internal/testdata"
+)
+
+func TestPathable(t *testing.T) {
+
+	var user testdata.User
+
+	chg := rift.SetMany(&user,
+		rift.Path("Name", "John"),
+		rift.Path("Tags.1", "b"),
+		rift.Path("Addresses.0.Street", "Main"),
+		rift.Path("Addresses.0.Number", 100),
+		rift.Path("Location.City", "Rome"),
+		rift.Path("Name", "Luke"),
+	)
+
+	assertEqual(t, testdata.User{
+		Name:      "Luke",
+		Tags:      []string{"", "b"},
+		Addresses: []testdata.Address{{Street: "Main", Number: 100}},
+		Location:  testdata.Location{City: "Rome"},
+	}, user, "generated SetPath")
+	assertEqual(t, []rift.Change{
+		{Path: "Name", Type: "string", New: "John", Old: ""},
+		{Path: "Tags.1", Type: "string", New: "b", Old: ""},
+		{Path: "Addresses.0.Street", Type: "string", New: "Main", Old: ""},
+		{Path: "Addresses.0.Number", Type: "int", New: 100, Old: 0},
+		{Path: "Location.City", Type: "string", New: "Rome", Old: ""},
+		{Path: "Name", Type: "string", New: "Luke", Old: "John"},
+	}, chg, "generated SetPath changes")
+
+	_, err := rift.TrySetPath(&user, "Age", "3")
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "generated SetPath type mismatch")
+	assertEqual(t, "rift: Age: type mismatch", err.Error(), "generated SetPath error path")
+
+	_, err = rift.TrySetPath(&user, "Addresses.0.Nope", 1)
+	assertEqual(t, "rift: Addresses.0.Nope: path not found", err.Error(), "nested error path")
+
+	v, err := rift.GetPath(user, "Addresses.0.Number")
+	assertEqual(t, 100, v, "generated GetPath")
+	assertEqual(t, nil, err, "generated GetPath")
+
+	n, err := rift.GetAs[string](user, "Location.City")
+	assertEqual(t, "Rome", n, "GetAs through generated GetPath")
+	assertEqual(t, nil, err, "GetAs through generated GetPath")
+
+	tree := rift.Get(struct{ User testdata.User }{user})
+	assertEqual(t, rift.Node{Type: "struct", Next: []rift.Node{
+		{Name: "User", Path: "User", Type: "struct", Next: []rift.Node{
+			{Name: "Name", Path: "User.Name", Type: "string", Data: "Luke"},
+			{Name: "Age", Path: "User.Age", Type: "int", Data: 0},
+			{Name: "Tags", Path: "User.Tags", Type: "slice", Next: []rift.Node{
+				{Name: "0", Path: "User.Tags.0", Type: "string", Data: ""},
+				{Name: "1", Path: "User.Tags.1", Type: "string", Data: "b"},
+			}},
+			{Name: "Addresses", Path: "User.Addresses", Type: "slice", Next: []rift.Node{
+				{Name: "0", Path: "User.Addresses.0", Type: "struct", Next: []rift.Node{
+					{Name: "Street", Path: "User.Addresses.0.Street", Type: "string", Data: "Main"},
+					{Name: "Number", Path: "User.Addresses.0.Number", Type: "int", Data: 100},
+				}},
+			}},
+			{Name: "Location", Path: "User.Location", Type: "struct", Next: []rift.Node{
+				{Name: "City", Path: "User.Location.City", Type: "string", Data: "Rome"},
+			}},
+		}},
+	}}, tree, "generated Get")
+}
+
+func TestPathableAsReflection(t *testing.T) {
+
+	// user mirrors testdata.User, which is generated, to go through reflection.
+	type user struct {
+		Name      string
+		Age       int
+		Tags      []string
+		Addresses []testdata.Address
+		testdata.Location
+	}
+
+	var gen testdata.User
+	var ref user
+
+	for _, n := range []rift.Node{
+		rift.Path("Name", "John"),
+		rift.Path("City", "Rome"),
+		rift.Path("Location.City", "Oslo"),
+		rift.Path("Tags.1", "b"),
+		rift.Path("Name", nil),
+		rift.Path("Age", nil),
+		rift.Path("Tags", nil),
+		rift.Path("Nope", 1),
+		rift.Path("Age", "x"),
+	} {
+		gc, gerr := rift.TrySetPath(&gen, n.Path, n.Data)
+		rc, rerr := rift.TrySetPath(&ref, n.Path, n.Data)
+		assertEqual(t, rc, gc, n.Path)
+		assertEqual(t, rerr, gerr, n.Path)
+
+		gv, gerr := rift.GetPath(gen, n.Path)
+		rv, rerr := rift.GetPath(ref, n.Path)
+		assertEqual(t, rv, gv, n.Path)
+		assertEqual(t, rerr, gerr, n.Path)
+	}
+	assertEqual(t, testdata.User{Location: testdata.Location{City: "Oslo"}}, gen, "generated")
+
+	gc := rift.Set(&gen, rift.Node{Next: []rift.Node{rift.Path("Name", nil), rift.Path("City", "Rome")}})
+	rc := rift.Set(&ref, rift.Node{Next: []rift.Node{rift.Path("Name", nil), rift.Path("City", "Rome")}})
+	assertEqual(t, rc, gc, "set")
+}
+
+func TestPathableOptions(t *testing.T) {
+
+	user := testdata.User{Name: "John", Tags: []string{"a"}, Location: testdata.Location{City: "Rome"}}
+
+	n, _ := rift.Get(user, rift.FlattenEmbedded()).Find("City")
+	assertEqual(t, rift.Node{Name: "City", Path: "City", Type: "string", Data: "Rome"}, n, "flattened")
+
+	n, _ = rift.Get(struct{ U testdata.User }{user}, rift.FullTypes()).Find("U.Tags")
+	assertEqual(t, "[]string", n.GoType, "full types")
+
+	c, err := rift.TrySetPath(&user, "Location.City", "Oslo", rift.FlattenEmbedded())
+	assertEqual(t, rift.Change{Path: "City", Type: "string", New: "Oslo", Old: "Rome"}, c, "flattened change")
+	assertEqual(t, nil, err, "flattened change")
+
+	cs, err := rift.Apply(&user, []rift.Node{rift.Path("Tags.2", "c"), rift.Path("Nope", 1)}, rift.Atomic())
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "atomic")
+	assertEqual(t, rift.ChangeSet(nil), cs, "atomic")
+	assertEqual(t, []string{"a"}, user.Tags, "atomic undoes the growth")
+
+	var h struct{ U testdata.User }
+
+	c, err = rift.TrySetPath(&h, "u.name", "y", rift.IgnoreCase())
+	assertEqual(t, rift.Change{Path: "U.Name", Type: "string", New: "y", Old: ""}, c, "case ignored")
+	assertEqual(t, nil, err, "case ignored")
+
+	v, err := rift.GetAs[string](h, "U.NAME", rift.IgnoreCase())
+	assertEqual(t, "y", v, "case ignored")
+	assertEqual(t, nil, err, "case ignored")
+}
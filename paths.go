@@ -0,0 +1,26 @@
+package rift
+
+import "strconv"
+
+// SlicePath is the path of a slice whose elements have paths of type E.
+// It is used by the path types generated by riftgen.
+type SlicePath[E ~string] string
+
+// At returns the path of the element i.
+func (p SlicePath[E]) At(i int) E {
+	return E(JoinPath(string(p), strconv.Itoa(i)))
+}
+
+// MapPath is the path of a map whose values have paths of type E.
+// It is used by the path types generated by riftgen.
+type MapPath[E ~string] string
+
+// Key returns the path of the value at key k.
+func (p MapPath[E]) Key(k string) E {
+	return E(JoinPath(string(p), k))
+}
+
+// JoinPath joins two paths.
+func JoinPath(path, subpath string) string {
+	return joinPath(path, subpath)
+}
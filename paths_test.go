@@ -0,0 +1,25 @@
+package rift_test
+
+import (
+	"testing"
+
+	"github.com/ofabricio/rift"
+	"github.com/ofabricio/rift/internal/testdata"
+)
+
+func TestGeneratedPaths(t *testing.T) {
+
+	assertEqual(t, "Name", testdata.UserPaths.Name(), "field")
+	assertEqual(t, "Tags.2", testdata.UserPaths.Tags().At(2), "slice element")
+	assertEqual(t, "Addresses.1.Street", testdata.UserPaths.Addresses().At(1).Street(), "struct in slice")
+	assertEqual(t, "Street", testdata.AddressPaths.Street(), "other root")
+
+	var user testdata.User
+	rift.SetPath(&user, testdata.UserPaths.Addresses().At(0).Number(), 100)
+	assertEqual(t, []testdata.Address{{Number: 100}}, user.Addresses, "usable with SetPath")
+}
+
+func TestMapPath(t *testing.T) {
+	p := rift.MapPath[rift.SlicePath[string]]("Map")
+	assertEqual(t, "Map.a.0", p.Key("a").At(0), "map of slices")
+}
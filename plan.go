@@ -0,0 +1,292 @@
+package rift
+
+import (
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// plan is a path compiled against a static type.
+type plan struct {
+	steps []step
+	leaf  reflect.Type // Static type at the end of the path; nil if only known at runtime.
+}
+
+// step is one static segment of a plan.
+type step struct {
+	kind  reflect.Kind
+	field []int // Field index when kind is struct.
+}
+
+type planKey struct {
+	typ       reflect.Type
+	path      string
+	match     matchMode
+	accessors bool
+}
+
+var plans sync.Map // map[planKey]*plan
+
+// compile returns the plan of path on type t.
+// Plans are cached per type and path template,
+// except those traversing a map, whose keys are unbounded.
+func (c *config) compile(t reflect.Type, path string) (*plan, error) {
+	key := planKey{t, templatePath(path), c.match, c.accessors}
+	if p, ok := plans.Load(key); ok {
+		return p.(*plan), nil
+	}
+	p, cache, err := c.newPlan(t, path)
+	if err != nil {
+		return nil, &PathError{Path: path, Err: err}
+	}
+	if cache {
+		plans.Store(key, p)
+	}
+	return p, nil
+}
+
+func (c *config) newPlan(t reflect.Type, path string) (p *plan, cache bool, err error) {
+	p = &plan{}
+	cache = true
+	for seg := range segments(path) {
+		t = derefType(t)
+		if t == nil || t.Kind() == reflect.Interface {
+			return p, cache, nil
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := c.fieldByName(t, seg)
+			if !ok && c.accessors {
+				// Accessor properties are read at runtime; see plan.get.
+				if at, ok := accessorType(t, seg); ok {
+					t = at
+					continue
+				}
+			}
+			if !ok {
+				return nil, false, ErrNotFound
+			}
+			p.steps = append(p.steps, step{kind: t.Kind(), field: f.Index})
+			t = f.Type
+		case reflect.Slice, reflect.Array:
+			if _, ok := getNumber(seg); !ok {
+				return nil, false, ErrIndex
+			}
+			p.steps = append(p.steps, step{kind: t.Kind()})
+			t = t.Elem()
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return nil, false, ErrType
+			}
+			p.steps = append(p.steps, step{kind: t.Kind()})
+			t = t.Elem()
+			cache = false
+		default:
+			return nil, false, ErrNotFound
+		}
+	}
+	p.leaf = t
+	return p, cache, nil
+}
+
+// get returns the value at path following the plan.
+// The part of the path past the static steps is resolved at runtime.
+func (p *plan) get(c *config, v reflect.Value, path string) (reflect.Value, error) {
+	if c.accessors {
+		return c.getPath(v, path)
+	}
+	segs := strings.Split(path, ".")
+	if path == "" {
+		segs = nil
+	}
+	for i, s := range p.steps {
+		v = derefValue(v)
+		if !v.IsValid() {
+			return v, ErrNotFound
+		}
+		switch s.kind {
+		case reflect.Struct:
+			if _, ok := c.asPathable(v); ok {
+				return c.getPath(v, strings.Join(segs[i:], "."))
+			}
+			f, err := v.FieldByIndexErr(s.field)
+			if err != nil {
+				return f, ErrNotFound
+			}
+			v = f
+		case reflect.Slice, reflect.Array:
+			n, _ := getNumber(segs[i])
+			if n >= v.Len() {
+				return reflect.Value{}, ErrNotFound
+			}
+			v = v.Index(n)
+		case reflect.Map:
+			v = v.MapIndex(reflect.ValueOf(segs[i]).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return v, ErrNotFound
+			}
+		}
+	}
+	return c.getPath(v, strings.Join(segs[len(p.steps):], "."))
+}
+
+// canGet reports whether the value at the end of the plan can be read as t.
+func (p *plan) canGet(t reflect.Type) bool {
+	for l := p.leaf; ; l = l.Elem() {
+		if l == nil || l.Kind() == reflect.Interface || l.AssignableTo(t) {
+			return true
+		}
+		if l.Kind() != reflect.Pointer {
+			return false
+		}
+	}
+}
+
+// canSet reports whether a value of type t can be set at the end of the plan.
+func (p *plan) canSet(t reflect.Type) bool {
+	for l := p.leaf; ; l = l.Elem() {
+		if l == nil || t.AssignableTo(l) {
+			return true
+		}
+		if l.Kind() != reflect.Pointer {
+			return false
+		}
+	}
+}
+
+// getPath returns the value at path.
+func (c *config) getPath(v reflect.Value, path string) (reflect.Value, error) {
+	v, _, err := c.lookup(v, path)
+	return v, err
+}
+
+// lookup returns the value at path and the segments of its path,
+// with the fields resolved as [config.fieldPath] does.
+func (c *config) lookup(v reflect.Value, path string) (reflect.Value, []string, error) {
+	var segs []string
+	for path != "" {
+		v = derefValue(v)
+		if p, ok := c.asPathable(v); ok {
+			r, err := p.GetPath(path)
+			return reflect.ValueOf(r), append(segs, c.pathSegments(v.Type(), path)...), err
+		}
+		seg, rest, _ := strings.Cut(path, ".")
+		switch v.Kind() {
+		case reflect.Struct:
+			if c.accessors {
+				name := c.accessorName(v.Type(), seg)
+				if g, ok := getter(v, name); ok {
+					segs = append(segs, name)
+					v = g
+					break
+				}
+			}
+			f, ok := c.fieldByName(v.Type(), seg)
+			if !ok {
+				return reflect.Value{}, nil, ErrNotFound
+			}
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil {
+				return reflect.Value{}, nil, ErrNotFound
+			}
+			segs = append(segs, c.fieldPath(v.Type(), f, rest == "")...)
+			v = fv
+		case reflect.Slice, reflect.Array:
+			n, ok := getNumber(seg)
+			if !ok {
+				return reflect.Value{}, nil, ErrIndex
+			}
+			if n >= v.Len() {
+				return reflect.Value{}, nil, ErrNotFound
+			}
+			segs = append(segs, strconv.Itoa(n))
+			v = v.Index(n)
+		case reflect.Map:
+			k, ok := getKey(v.Type(), seg)
+			if !ok {
+				return reflect.Value{}, nil, ErrType
+			}
+			segs = append(segs, seg)
+			v = v.MapIndex(k)
+		default:
+			return reflect.Value{}, nil, ErrNotFound
+		}
+		if !v.IsValid() {
+			return v, nil, ErrNotFound
+		}
+		path = rest
+	}
+	return v, segs, nil
+}
+
+// pathSegments returns the segments of path on type t, with the fields
+// resolved as [config.fieldPath] does, as far as t tells them.
+// It gives the paths handled by a [Pathable] type as reflection does.
+func (c *config) pathSegments(t reflect.Type, path string) []string {
+	var out []string
+	for path != "" {
+		seg, rest, _ := strings.Cut(path, ".")
+		switch t = derefType(t); t.Kind() {
+		case reflect.Struct:
+			f, ok := c.fieldByName(t, seg)
+			if !ok {
+				return append(out, path)
+			}
+			out = append(out, c.fieldPath(t, f, rest == "")...)
+			t = f.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			out = append(out, seg)
+			t = t.Elem()
+		default:
+			// Interfaces tell nothing of the paths under them.
+			return append(out, path)
+		}
+		path = rest
+	}
+	return out
+}
+
+// segments iterates over the segments of a path.
+func segments(path string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if path == "" {
+			return
+		}
+		for s := range strings.SplitSeq(path, ".") {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+}
+
+// templatePath replaces the indexes of a path with [].
+// Segments starting with [ get another one, so that a
+// segment "[]", which is not an index, keeps its own plan.
+func templatePath(path string) string {
+	segs := strings.Split(path, ".")
+	for i, s := range segs {
+		if _, ok := getNumber(s); ok {
+			segs[i] = "[]"
+		} else if strings.HasPrefix(s, "[") {
+			segs[i] = "[" + s
+		}
+	}
+	return strings.Join(segs, ".")
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func derefValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package rift
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Redact masks the sensitive values in the changes and in the nodes
+// returned by [Get], replacing them by [Redacted] values, while they
+// are still set as usual. Sensitive values are those of the fields
+// tagged `rift:",secret"`, and of the values under them, and those
+// at paths matching any of the patterns, as [ChangeSet.Match] does.
+//
+// The Old and New values of redacted changes are lost, so they cannot
+// be inverted by [ChangeSet.Invert]. [Atomic] still undoes them.
+// The typed changes of [SetAs] are never redacted.
+func Redact(patterns ...string) Option {
+	return func(c *config) {
+		c.redact = true
+		c.secrets = append(c.secrets, patterns...)
+	}
+}
+
+// Redacted replaces a sensitive value. Its Hash identifies the value
+// without revealing it, so that a change of a redacted value can be told
+// from a no-op one. Hashes are keyed per process, so they can only be
+// compared within it.
+type Redacted struct {
+	Hash string
+}
+
+func (r Redacted) String() string {
+	return "[REDACTED]"
+}
+
+// redactKey keys the hashes of the redacted values.
+var redactKey = func() []byte {
+	k := make([]byte, 32)
+	rand.Read(k)
+	return k
+}()
+
+// redacted returns v redacted. Nil stays nil, as it reveals nothing.
+func redacted(v any) any {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b = fmt.Appendf(nil, "%#v", v)
+	}
+	h := hmac.New(sha256.New, redactKey)
+	h.Write(b)
+	return Redacted{Hash: hex.EncodeToString(h.Sum(nil)[:8])}
+}
+
+// secret reports whether the value at path of dst is redacted.
+func (c *config) secret(dst reflect.Value, path string) bool {
+	if !c.redact {
+		return false
+	}
+	if c.secretPath(path) {
+		return true
+	}
+	fs, _ := c.fieldsAt(dst, path)
+	return slices.ContainsFunc(fs, isSecret)
+}
+
+// secretPath reports whether path matches any of the redacted patterns.
+func (c *config) secretPath(path string) bool {
+	segs := strings.Split(path, ".")
+	return slices.ContainsFunc(c.secrets, func(p string) bool {
+		return matchPath(strings.Split(p, "."), segs)
+	})
+}
+
+// getSecret sets out to the redacted node of v, without children.
+func (c *config) getSecret(v reflect.Value, path string, out *Node) {
+	out.Path = path
+	if c.fullTypes && v.IsValid() && v.Kind() != reflect.Interface {
+		out.GoType = v.Type().String()
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		out.Type = v.Type().Elem().Kind().String()
+		return
+	}
+	if v = derefValue(v); v.IsValid() && v.CanInterface() {
+		out.Data = redacted(v.Interface())
+	}
+	out.Type = getKind(v)
+}
+
+func isSecret(f reflect.StructField) bool {
+	return parseTag(f.Tag).has("secret")
+}
+
+// redactAll redacts the sensitive values of the changes to dst.
+func (c *config) redactAll(dst any, cs []Change) {
+	if !c.redact || c.undo {
+		return
+	}
+	for i := range cs {
+		if c.secret(reflect.ValueOf(dst), cs[i].Path) {
+			cs[i].Old = redacted(cs[i].Old)
+			cs[i].New = redacted(cs[i].New)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package rift_test
+
+import (
+	"testing"
+
+	"github.com/ofabricio/rift"
+	"github.com/ofabricio/rift/internal/testdata"
+)
+
+func TestRedact(t *testing.T) {
+
+	type Credentials struct {
+		Password string `rift:",secret"`
+		Token    *string
+	}
+	type Account struct {
+		Name    string
+		Creds   Credentials
+		Keys    []string `rift:",secret"`
+		Headers map[string]string
+	}
+
+	give := Account{Name: "John", Creds: Credentials{Password: "old"}, Headers: map[string]string{"Auth": "x"}}
+	opts := []rift.Option{rift.Redact("Creds.Token", "Headers.Auth")}
+
+	cs, err := rift.Apply(&give, []rift.Node{
+		rift.Path("Name", "Luke"),
+		rift.Path("Creds.Password", "new"),
+		rift.Path("Creds.Token", "abc"),
+		rift.Path("Keys.0", "k"),
+		rift.Path("Headers.Auth", "y"),
+	}, opts...)
+	assertEqual(t, nil, err, "apply")
+	assertEqual(t, Account{Name: "Luke", Creds: Credentials{Password: "new", Token: ptr("abc")}, Keys: []string{"k"}, Headers: map[string]string{"Auth": "y"}}, give, "assigned")
+
+	assertEqual(t, rift.Change{Path: "Name", Type: "string", New: "Luke", Old: "John"}, cs[0], "not redacted")
+	for _, c := range cs[1:] {
+		assertEqual(t, true, isRedacted(c.New), c.Path)
+		assertEqual(t, true, c.Old == nil || isRedacted(c.Old), c.Path)
+		assertEqual(t, true, c.Old != c.New, c.Path+" changed")
+	}
+	assertEqual(t, "[REDACTED]", cs[1].New.(rift.Redacted).String(), "string")
+
+	c := rift.SetPath(&give, "Creds.Password", "new", opts...)
+	assertEqual(t, cs[1].New, c.New, "same value, same hash")
+	assertEqual(t, c.Old, c.New, "unchanged")
+	assertEqual(t, 0, len(rift.ChangeSet{c}.Effective()), "unchanged")
+
+	var data []any
+	for _, n := range rift.GetFlat(give, opts...) {
+		data = append(data, n.Data)
+	}
+	assertEqual(t, "Luke", data[0], "get name")
+	for _, d := range data[1:] {
+		assertEqual(t, true, isRedacted(d), "get redacted")
+	}
+	assertEqual(t, 5, len(data), "secret slices are leaves")
+
+	n := rift.Get(give)
+	assertEqual(t, "new", n.Next[1].Next[0].Data, "get without option")
+
+	user := testdata.User{Name: "john"}
+	n, _ = rift.Get(user, rift.Redact("Name")).Find("Name")
+	assertEqual(t, true, isRedacted(n.Data), "generated type")
+
+	n, _ = rift.Get(struct{ U testdata.User }{user}, rift.Redact("U.Name")).Find("U.Name")
+	assertEqual(t, true, isRedacted(n.Data), "under a generated type")
+
+	c = rift.SetPath(&user, "Name", "luke", rift.Redact("Name"))
+	assertEqual(t, true, isRedacted(c.Old) && isRedacted(c.New), "set a generated type")
+}
+
+func isRedacted(v any) bool {
+	_, ok := v.(rift.Redacted)
+	return ok
+}
@@ -7,32 +7,40 @@ import (
 )
 
 // Get returns a tree representation of the provided value.
-func Get(v any) Node {
+func Get(v any, opts ...Option) Node {
 	var out Node
-	get(reflect.ValueOf(v), "", &out)
+	newConfig(opts).get(reflect.ValueOf(v), "", &out)
 	return out
 }
 
-func get(v reflect.Value, path string, out *Node) {
+func (c *config) get(v reflect.Value, path string, out *Node) {
+	if c.redact && c.secretPath(path) {
+		c.getSecret(v, path, out)
+		return
+	}
+	name := out.Name
 	out.Path = path
 	out.Type = v.Kind().String()
+	if c.fullTypes && out.GoType == "" && v.IsValid() && v.Kind() != reflect.Interface {
+		out.GoType = v.Type().String()
+	}
 	switch v.Kind() {
 	case reflect.Invalid:
 		out.Type = reflect.Interface.String()
 	case reflect.Interface:
-		get(v.Elem(), path, out)
+		c.get(v.Elem(), path, out)
 	case reflect.Pointer:
 		if v.IsNil() {
 			out.Type = v.Type().Elem().Kind().String()
 			return
 		}
-		get(v.Elem(), path, out)
+		c.get(v.Elem(), path, out)
 	case reflect.Slice:
 		for i := range v.Len() {
 			f := v.Index(i)
 			p := strconv.Itoa(i)
 			n := Node{Name: p}
-			get(f, joinPath(path, p), &n)
+			c.get(f, joinPath(path, p), &n)
 			out.Next = append(out.Next, n)
 		}
 	case reflect.Map:
@@ -41,26 +49,65 @@ func get(v reflect.Value, path string, out *Node) {
 			v := iter.Value()
 			p := k.String()
 			n := Node{Name: p}
-			get(v, joinPath(path, p), &n)
+			c.get(v, joinPath(path, p), &n)
 			out.Next = append(out.Next, n)
 		}
 	case reflect.Struct:
-		for i := range v.NumField() {
-			f := v.Field(i)
-			p := v.Type().Field(i).Name
-			n := Node{Name: p}
-			get(f, joinPath(path, p), &n)
+		if p, ok := c.asPathable(v); ok {
+			goType := out.GoType
+			*out = p.Get().Rebase(path)
+			out.Name = name
+			out.GoType = goType
+			return
+		}
+		for _, f := range c.structFields(v.Type()) {
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil {
+				// Promoted through a nil embedded pointer.
+				fv = reflect.Zero(reflect.PointerTo(derefType(f.Type)))
+			}
+			if c.accessors {
+				if g, ok := getter(v, f.Name); ok {
+					fv = g
+				}
+			}
+			n := Node{Name: f.Name}
+			if c.redact && isSecret(f) {
+				c.getSecret(fv, joinPath(path, f.Name), &n)
+			} else {
+				c.get(fv, joinPath(path, f.Name), &n)
+			}
 			out.Next = append(out.Next, n)
 		}
+		if out.Next == nil && v.CanInterface() {
+			// Opaque values, such as time.Time.
+			out.Data = v.Interface()
+		}
 	default:
 		out.Data = v.Interface()
 	}
 }
 
+// GetPath returns the value at path.
+// See [IgnoreCase] for the options that apply.
+func GetPath(v any, path string, opts ...Option) (any, error) {
+	r, err := newConfig(opts).getPath(reflect.ValueOf(v), path)
+	if err != nil {
+		return nil, pathError(path, err)
+	}
+	if !r.IsValid() {
+		return nil, nil
+	}
+	if !r.CanInterface() {
+		return nil, &PathError{Path: path, Err: ErrNotFound}
+	}
+	return r.Interface(), nil
+}
+
 // GetFlat returns a flat representation of the provided value.
-func GetFlat(v any) []Node {
+func GetFlat(v any, opts ...Option) []Node {
 	var out []Node
-	walk(Get(v), func(n Node) {
+	walk(Get(v, opts...), func(n Node) {
 		if len(n.Next) == 0 {
 			n.Name = ""
 			out = append(out, n)
@@ -70,13 +117,18 @@ func GetFlat(v any) []Node {
 }
 
 // Set sets values to a struct based on the provided node.
-func Set(dst any, n Node) []Change {
-	var chgs []Change
-	walk(n, func(n Node) {
-		if len(n.Next) == 0 {
-			chgs = append(chgs, SetPath(dst, n.Path, n.Data))
-		}
-	})
+// Only nodes without children are set, unless [Replace] is used,
+// and nodes with Op [OpDelete] remove the value at their path.
+// Paths not found are ignored, as [SetPath] does.
+// It panics with a [*PathError] if a value cannot be set,
+// or with a [ValidationError] if a value breaks a rule.
+func Set(dst any, n Node, opts ...Option) []Change {
+	c := newConfig(opts)
+	c.lenient = true
+	chgs, err := c.apply(dst, []Node{n})
+	if err != nil {
+		panic(err)
+	}
 	return chgs
 }
 
@@ -88,99 +140,437 @@ func walk(n Node, fn func(Node)) {
 }
 
 // SetMany sets values to a struct based on the provided nodes.
+// Paths not found are ignored, as [SetPath] does.
+// It panics with a [*PathError] if a value cannot be set,
+// or with a [ValidationError] if a value breaks a rule.
+// See [Apply] to use options.
 func SetMany(dst any, ns ...Node) []Change {
-	chg := make([]Change, 0, len(ns))
-	for _, n := range ns {
-		chg = append(chg, SetPath(dst, n.Path, n.Data))
+	c := newConfig(nil)
+	c.lenient = true
+	chgs, err := c.apply(dst, ns)
+	if err != nil {
+		panic(err)
 	}
-	return chg
+	return chgs
 }
 
 // SetPath sets a value to a struct based on the provided path.
-func SetPath(dst any, path string, val any) Change {
-	d := reflect.ValueOf(dst)
-	v := reflect.ValueOf(val)
-	old := setPath(d, v, path)
-	return Change{Path: path, New: val, Old: old, Type: getType(v)}
+// A path not found, such as an unknown field, is ignored,
+// and its change has no Old value.
+// It panics with a [*PathError] if the value cannot be set,
+// or with a [ValidationError] if it breaks a rule.
+func SetPath(dst any, path string, val any, opts ...Option) Change {
+	c := newConfig(opts)
+	c.lenient = true
+	chg, err := c.trySetPath(dst, path, val)
+	if err != nil {
+		panic(err)
+	}
+	return chg
 }
 
-func setPath(dst, val reflect.Value, path string) (old any) {
+// TrySetPath is like [SetPath] but returns an error instead of panicking,
+// and returns [ErrNotFound] for a path not found instead of ignoring it.
+func TrySetPath(dst any, path string, val any, opts ...Option) (Change, error) {
+	return newConfig(opts).trySetPath(dst, path, val)
+}
+
+func (c *config) trySetPath(dst any, path string, val any) (Change, error) {
+	if err := c.validate(dst, []Node{Path(path, val)}); err != nil {
+		return Change{}, err
+	}
+	cs, err := c.setPath(dst, path, val)
+	if err != nil {
+		return Change{}, err
+	}
+	return cs[len(cs)-1], nil
+}
+
+// SetPathIf is like [TrySetPath], but sets the value only if the
+// value at path equals expected, and otherwise returns [ErrConflict].
+// Values are compared as in [FlagNoop], and a missing value equals nil.
+func SetPathIf(dst any, path string, expected, val any, opts ...Option) (Change, error) {
+	c := newConfig(opts)
+	if err := c.test(dst, path, expected); err != nil {
+		return Change{}, err
+	}
+	if err := c.validate(dst, []Node{Path(path, val)}); err != nil {
+		return Change{}, err
+	}
+	cs, err := c.setPath(dst, path, val)
+	if err != nil {
+		return Change{}, err
+	}
+	return cs[len(cs)-1], nil
+}
+
+// setAt sets val at path and returns the old value.
+func (c *config) setAt(dst, val reflect.Value, path string) (old any, err error) {
+	s := setter{config: c}
+	return s.set(dst, val, path)
+}
+
+// setter sets a value at path.
+type setter struct {
+	*config
+	segs []string // Segments of the path set so far.
+	chgs []Change // Structural changes.
+}
+
+// canonical returns the path set so far.
+func (s *setter) canonical() string {
+	return strings.Join(s.segs, ".")
+}
+
+// record records a structural change at the path set so far.
+func (s *setter) record(op Op, typ reflect.Type, old, new any) {
+	if s.structural || s.undo {
+		s.chgs = append(s.chgs, Change{Op: op, Path: s.canonical(), Type: typ.Name(), Old: old, New: new})
+	}
+}
+
+func (s *setter) set(dst, val reflect.Value, path string) (old any, err error) {
 
 	keyOrIdx, rest, _ := strings.Cut(path, ".")
 
+	// The root pointer is not settable, so it is set through.
+	if path == "" && dst.IsValid() && (dst.Kind() != reflect.Pointer || dst.CanSet()) {
+		if !val.IsValid() {
+			return setZero(dst)
+		}
+		switch dst.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			return assign(dst, val)
+		case reflect.Pointer:
+			if val.Type().AssignableTo(dst.Type()) {
+				old, err = assign(dst, val)
+				if v := reflect.ValueOf(old); v.IsValid() && v.IsNil() {
+					old = nil // A nil pointer has no old value.
+				}
+				return old, err
+			}
+		}
+	}
+
 	switch dst.Kind() {
 	case reflect.Invalid:
 	case reflect.Pointer:
 		if dst.IsNil() {
+			if !dst.CanSet() {
+				return nil, ErrNotSettable
+			}
+			n := len(s.chgs)
 			dst.Set(reflect.New(dst.Type().Elem()))
-			_ = setPath(dst.Elem(), val, path)
-			old = nil
+			s.record(OpAlloc, dst.Type().Elem(), nil, nil)
+			if err = setDefaults(dst.Elem(), "", nil); err == nil {
+				old, err = s.set(dst.Elem(), val, path)
+			}
+			if err != nil {
+				// Nothing was set under the allocation.
+				dst.SetZero()
+				s.chgs = s.chgs[:n]
+				return nil, err
+			}
+			if !s.structural {
+				old = nil
+			}
 		} else {
-			old = setPath(dst.Elem(), val, path)
+			old, err = s.set(dst.Elem(), val, path)
 		}
 	case reflect.Interface:
 		if path == "" {
+			if val.IsValid() && !val.Type().AssignableTo(dst.Type()) {
+				return nil, ErrType
+			}
 			old = dst.Interface()
-			dst.Set(val)
-		} else if n, ok := getNumber(keyOrIdx); ok {
-			if dst.IsNil() {
+			if val.IsValid() {
+				dst.Set(val)
+			} else {
+				dst.SetZero()
+			}
+		} else if dst.IsNil() {
+			if n, ok := getNumber(keyOrIdx); ok {
 				new := reflect.MakeSlice(reflect.TypeFor[[]any](), n+1, n+1)
 				dst.Set(new)
-			} else if n >= dst.Elem().Len() {
-				new := reflect.MakeSlice(dst.Elem().Type(), n+1, n+1)
-				reflect.Copy(new, dst.Elem())
+				s.record(OpMake, new.Type(), nil, nil)
+				s.record(OpGrow, new.Type(), 0, n+1)
+			} else {
+				new := reflect.MakeMap(reflect.TypeFor[map[string]any]())
 				dst.Set(new)
+				s.record(OpMake, new.Type(), nil, nil)
 			}
-			old = setPath(dst.Elem().Index(n), val, rest)
-		} else {
-			if dst.IsNil() {
-				new := reflect.MakeMap(reflect.TypeFor[map[string]any]())
+			old, err = s.set(dst.Elem(), val, path)
+		} else if e := dst.Elem(); e.Kind() == reflect.Struct || e.Kind() == reflect.Array {
+			// Values held by interfaces are not addressable.
+			if !dst.CanSet() {
+				return nil, ErrNotSettable
+			}
+			c := reflect.New(e.Type()).Elem()
+			c.Set(e)
+			if old, err = s.set(c, val, path); err == nil {
+				dst.Set(c)
+			}
+		} else if n, ok := getNumber(keyOrIdx); ok && e.Kind() == reflect.Slice {
+			if l := e.Len(); n >= l {
+				new, err := grow(e, n+1)
+				if err != nil {
+					return nil, err
+				}
 				dst.Set(new)
+				s.record(OpGrow, new.Type(), l, n+1)
 			}
-			old = setPath(dst.Elem(), val, path)
+			s.segs = append(s.segs, strconv.Itoa(n))
+			old, err = s.set(dst.Elem().Index(n), val, rest)
+		} else {
+			old, err = s.set(e, val, path)
 		}
 	case reflect.Slice:
-		n, _ := getNumber(keyOrIdx)
-		if n >= dst.Len() {
-			new := reflect.MakeSlice(dst.Type(), n+1, n+1)
-			reflect.Copy(new, dst)
+		n, ok := getNumber(keyOrIdx)
+		if !ok {
+			return nil, ErrIndex
+		}
+		if l := dst.Len(); n >= l {
+			if dst.IsNil() {
+				s.record(OpMake, dst.Type(), nil, nil)
+			}
+			new, err := grow(dst, n+1)
+			if err != nil {
+				return nil, err
+			}
 			dst.Set(new)
+			s.record(OpGrow, dst.Type(), l, n+1)
 		}
-		old = setPath(dst.Index(n), val, rest)
+		s.segs = append(s.segs, strconv.Itoa(n))
+		old, err = s.set(dst.Index(n), val, rest)
 	case reflect.Map:
 		if dst.IsNil() {
 			dst.Set(reflect.MakeMap(dst.Type()))
+			s.record(OpMake, dst.Type(), nil, nil)
 		}
-		k := reflect.ValueOf(keyOrIdx)
-		v := dst.MapIndex(k)
-		if !v.IsValid() {
-			v = reflect.New(dst.Type().Elem()).Elem()
+		k, ok := getKey(dst.Type(), keyOrIdx)
+		if !ok {
+			return nil, ErrType
 		}
-		new := reflect.New(v.Type()).Elem()
-		new.Set(v)
-		old = setPath(new, val, rest)
-		dst.SetMapIndex(k, new.Elem())
+		s.segs = append(s.segs, keyOrIdx)
+		// Map values are not addressable.
+		c := reflect.New(dst.Type().Elem()).Elem()
+		if v := dst.MapIndex(k); v.IsValid() {
+			c.Set(v)
+		} else {
+			s.record(OpAdd, dst.Type().Elem(), nil, nil)
+			if err := setDefaults(c, "", nil); err != nil {
+				return nil, err
+			}
+		}
+		if old, err = s.set(c, val, rest); err == nil {
+			dst.SetMapIndex(k, c)
+		}
+	case reflect.Array:
+		n, ok := getNumber(keyOrIdx)
+		if !ok || n >= dst.Len() {
+			return nil, ErrIndex
+		}
+		s.segs = append(s.segs, strconv.Itoa(n))
+		old, err = s.set(dst.Index(n), val, rest)
 	case reflect.Struct:
-		key := dst.FieldByName(keyOrIdx)
-		old = setPath(key, val, rest)
+		old, err = s.setStruct(dst, val, path)
 	default:
-		old = dst.Interface()
-		dst.Set(val)
+		if path != "" {
+			return nil, ErrNotFound
+		}
+		return assign(dst, val)
 	}
 	return
 }
 
+// setStruct sets val at path of the struct dst,
+// calling its [BeforeSetter] and [AfterSetter] hooks.
+func (s *setter) setStruct(dst, val reflect.Value, path string) (old any, err error) {
+	h := hooks(dst)
+	if b, ok := h.(BeforeSetter); ok {
+		if err := b.BeforeSetPath(path, valueOf(val)); err != nil {
+			return nil, err
+		}
+	}
+	n := len(s.segs)
+	if old, err = s.setField(dst, val, path); err != nil {
+		return nil, err
+	}
+	if a, ok := h.(AfterSetter); ok {
+		a.AfterSetPath(Change{Path: strings.Join(s.segs[n:], "."), Type: getType(val), New: valueOf(val), Old: old})
+	}
+	return old, nil
+}
+
+func (s *setter) setField(dst, val reflect.Value, path string) (old any, err error) {
+	keyOrIdx, rest, _ := strings.Cut(path, ".")
+	if p, ok := s.addrPathable(dst); ok {
+		s.segs = append(s.segs, s.pathSegments(dst.Type(), path)...)
+		return p.SetPath(path, valueOf(val))
+	}
+	if s.accessors {
+		if old, ok, err := s.setAccessor(dst, val, s.accessorName(dst.Type(), keyOrIdx), rest); ok {
+			return old, err
+		}
+	}
+	f, ok := s.fieldByName(dst.Type(), keyOrIdx)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	v := dst
+	var hidden []string // Segments of the flattened structs since the last segment.
+	for j, i := range f.Index {
+		// Fields promoted from embedded pointers.
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return nil, ErrNotSettable
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+				// Recorded at the path of the pointer, so that it can be undone.
+				n := len(s.segs)
+				s.segs = append(s.segs, hidden...)
+				s.record(OpAlloc, v.Type().Elem(), nil, nil)
+				s.segs = s.segs[:n]
+				if err := setDefaults(v.Elem(), "", nil); err != nil {
+					return nil, err
+				}
+			}
+			v = v.Elem()
+		}
+		sf := v.Type().Field(i)
+		v = v.Field(i)
+		if (rest == "" && j == len(f.Index)-1) || !s.flattened(sf) {
+			s.segs = append(s.segs, sf.Name)
+			hidden = nil
+		} else {
+			hidden = append(hidden, sf.Name)
+		}
+	}
+	return s.set(v, val, rest)
+}
+
+// grow returns a copy of slice v grown to length n,
+// with the defaults set to the new elements.
+func grow(v reflect.Value, n int) (reflect.Value, error) {
+	new := reflect.MakeSlice(v.Type(), n, n)
+	reflect.Copy(new, v)
+	for i := v.Len(); i < n; i++ {
+		if err := setDefaults(new.Index(i), "", nil); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return new, nil
+}
+
+// setZero sets dst to its zero value.
+func setZero(dst reflect.Value) (old any, err error) {
+	if !dst.CanSet() {
+		return nil, ErrNotSettable
+	}
+	if v := derefValue(dst); v.IsValid() {
+		old = v.Interface()
+	}
+	dst.SetZero()
+	return old, nil
+}
+
+func assign(dst, val reflect.Value) (old any, err error) {
+	if !val.IsValid() || !val.Type().AssignableTo(dst.Type()) {
+		return nil, ErrType
+	}
+	if !dst.CanSet() {
+		return nil, ErrNotSettable
+	}
+	old = dst.Interface()
+	dst.Set(val)
+	return old, nil
+}
+
 // Path creates a node with the specified path and value.
 func Path(path string, value any) Node {
 	return Node{Path: path, Data: value}
 }
 
+// Test creates a node that fails its batch with [ErrConflict]
+// unless the value at path equals value. See [SetPathIf].
+func Test(path string, value any) Node {
+	return Node{Op: OpTest, Path: path, Data: value}
+}
+
+// Rebase returns a copy of n with its paths prefixed by path,
+// as if n was the node at path.
+func (n Node) Rebase(path string) Node {
+	if path == "" {
+		return n
+	}
+	out := n
+	out.Path = joinPath(path, n.Path)
+	if n.Path == "" {
+		out.Name = path[strings.LastIndexByte(path, '.')+1:]
+	}
+	out.Next = make([]Node, len(n.Next))
+	for i, v := range n.Next {
+		out.Next[i] = v.Rebase(path)
+	}
+	if n.Next == nil {
+		out.Next = nil
+	}
+	return out
+}
+
 // Change represents a change.
 type Change struct {
+	Op   Op
 	Path string
 	Type string
-	New  any // New value set.
-	Old  any // Old value before set.
+	New  any  // New value set.
+	Old  any  // Old value before set.
+	Noop bool // Noop reports whether New was equal to Old and not set. See [FlagNoop].
+
+	GoType string `json:",omitempty"` // Go type at the path, as in *time.Time. See [FullTypes].
+	Kind   string `json:",omitempty"` // Kind of GoType, without pointers. See [FullTypes].
+}
+
+// Op is the operation of a change.
+type Op int
+
+const (
+	OpSet    Op = iota // A value was set.
+	OpDelete           // A slice element or map key was removed.
+
+	// Structural changes. See [Structural].
+
+	OpAlloc // A nil pointer was allocated.
+	OpMake  // A nil slice or map was created.
+	OpGrow  // A slice was grown from length Old to New.
+	OpAdd   // A map key was added.
+
+	OpTest // A value was compared. See [Test].
+)
+
+func (o Op) structural() bool {
+	return o == OpAlloc || o == OpMake || o == OpGrow || o == OpAdd
+}
+
+func (o Op) String() string {
+	switch o {
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	case OpAlloc:
+		return "alloc"
+	case OpMake:
+		return "make"
+	case OpGrow:
+		return "grow"
+	case OpAdd:
+		return "add"
+	case OpTest:
+		return "test"
+	}
+	return "op(" + strconv.Itoa(int(o)) + ")"
 }
 
 // Node is a node in the tree.
@@ -190,9 +580,19 @@ type Node struct {
 	Type string
 	Data any
 	Next []Node
+
+	Op Op `json:",omitempty"` // Operation applied by Set.
+
+	GoType string            `json:",omitempty"` // Go type, as in *time.Time. See [FullTypes].
+	Tag    reflect.StructTag `json:",omitempty"` // Tag of the struct field.
+	Leaf   bool              `json:",omitempty"` // Leaf reports whether the node is a scalar.
+	Ref    int               `json:",omitempty"` // Levels up to the node a recursive type repeats. See [Schema].
 }
 
 func joinPath(path, subpath string) string {
+	if subpath == "" {
+		return path
+	}
 	if path != "" {
 		return path + "." + subpath
 	}
@@ -201,7 +601,14 @@ func joinPath(path, subpath string) string {
 
 func getNumber(path string) (int, bool) {
 	v, err := strconv.Atoi(path)
-	return v, err == nil
+	return v, err == nil && v >= 0
+}
+
+func getKey(t reflect.Type, key string) (reflect.Value, bool) {
+	if t.Key().Kind() != reflect.String {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(key).Convert(t.Key()), true
 }
 
 func getType(v reflect.Value) string {
@@ -210,3 +617,10 @@ func getType(v reflect.Value) string {
 	}
 	return reflect.Interface.String()
 }
+
+func getKind(v reflect.Value) string {
+	if v.IsValid() {
+		return v.Kind().String()
+	}
+	return reflect.Interface.String()
+}
@@ -2,6 +2,7 @@ package rift_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -667,6 +668,124 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestSetNotFound(t *testing.T) {
+
+	type Point struct {
+		X int
+	}
+
+	var give Point
+
+	c := rift.SetPath(&give, "Y", 2)
+	assertEqual(t, rift.Change{Path: "Y", Type: "int", New: 2}, c, "unknown field is ignored")
+
+	cs := rift.Set(&give, rift.Get(struct{ X, Y int }{X: 1, Y: 2}))
+	assertEqual(t, Point{X: 1}, give, "set from another type")
+	assertEqual(t, []rift.Change{
+		{Path: "X", Type: "int", New: 1, Old: 0},
+		{Path: "Y", Type: "int", New: 2},
+	}, cs, "set from another type")
+
+	type Other struct {
+		X     int
+		Extra struct{ Y int }
+	}
+	cs = rift.Set(&give, rift.Get(Other{X: 4, Extra: struct{ Y int }{5}}), rift.Replace())
+	assertEqual(t, Point{X: 4}, give, "unknown subtree is ignored with replace")
+	assertEqual(t, []rift.Change{
+		{Path: "X", Type: "int", New: 4, Old: 1},
+		{Path: "Extra.Y", Type: "int", New: 5},
+	}, cs, "unknown subtree is ignored with replace")
+
+	_, err := rift.Apply(&give, []rift.Node{rift.Get(Other{})}, rift.Replace())
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "apply reports it with replace")
+
+	cs = rift.SetMany(&give, rift.Path("X.Y", 3))
+	assertEqual(t, []rift.Change{{Path: "X.Y", Type: "int", New: 3}}, cs, "path under a scalar is ignored")
+
+	_, err = rift.TrySetPath(&give, "Y", 2)
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "try reports it")
+
+	_, err = rift.Apply(&give, []rift.Node{rift.Path("Y", 2)})
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "apply reports it")
+}
+
+func TestSetNonAddressable(t *testing.T) {
+
+	type Point struct {
+		X, Y int
+	}
+
+	tt := []struct {
+		Desc string
+		Give any
+		When []rift.Node
+		Then any
+		Bnds []rift.Change
+	}{
+		{
+			Desc: "struct held by an interface",
+			Give: Point{X: 1},
+			When: []rift.Node{rift.Path("Y", 2)},
+			Then: Point{X: 1, Y: 2},
+			Bnds: []rift.Change{{Path: "Y", Type: "int", New: 2, Old: 0}},
+		},
+		{
+			Desc: "array held by an interface",
+			Give: [2]int{1, 2},
+			When: []rift.Node{rift.Path("1", 3)},
+			Then: [2]int{1, 3},
+			Bnds: []rift.Change{{Path: "1", Type: "int", New: 3, Old: 2}},
+		},
+		{
+			Desc: "struct map values",
+			Give: map[string]Point{"a": {X: 1}},
+			When: []rift.Node{rift.Path("a.Y", 2), rift.Path("b.X", 3)},
+			Then: map[string]Point{"a": {X: 1, Y: 2}, "b": {X: 3}},
+			Bnds: []rift.Change{
+				{Path: "a.Y", Type: "int", New: 2, Old: 0},
+				{Path: "b.X", Type: "int", New: 3, Old: 0},
+			},
+		},
+		{
+			Desc: "scalar map values",
+			Give: map[string]int{"a": 1},
+			When: []rift.Node{rift.Path("a", 2)},
+			Then: map[string]int{"a": 2},
+			Bnds: []rift.Change{{Path: "a", Type: "int", New: 2, Old: 1}},
+		},
+		{
+			Desc: "nested maps of arrays",
+			Give: map[string]map[string][2]Point{"a": {"b": {}}},
+			When: []rift.Node{rift.Path("a.b.1.X", 1), rift.Path("c.d.0.Y", 2)},
+			Then: map[string]map[string][2]Point{"a": {"b": {{}, {X: 1}}}, "c": {"d": {{Y: 2}, {}}}},
+			Bnds: []rift.Change{
+				{Path: "a.b.1.X", Type: "int", New: 1, Old: 0},
+				{Path: "c.d.0.Y", Type: "int", New: 2, Old: 0},
+			},
+		},
+		{
+			Desc: "struct held by an interface in a map",
+			Give: map[string]any{"p": Point{X: 1}, "s": []any{Point{}}},
+			When: []rift.Node{rift.Path("p.Y", 2), rift.Path("s.0.X", 3)},
+			Then: map[string]any{"p": Point{X: 1, Y: 2}, "s": []any{Point{X: 3}}},
+			Bnds: []rift.Change{
+				{Path: "p.Y", Type: "int", New: 2, Old: 0},
+				{Path: "s.0.X", Type: "int", New: 3, Old: 0},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		bs := rift.SetMany(&tc.Give, tc.When...)
+		assertEqual(t, tc.Then, tc.Give, tc.Desc)
+		assertEqual(t, tc.Bnds, bs, tc.Desc)
+	}
+
+	_, err := rift.TrySetPath(&map[string][2]int{}, "a.2", 1)
+	assertEqual(t, "rift: a.2: invalid index", err.Error(), "array index out of range")
+}
+
 type TestData struct {
 	Int      int
 	IntPtr   *int
@@ -688,3 +807,170 @@ func assertEqual(t *testing.T, exp, got any, msgs ...any) {
 func ptr[T any](v T) *T {
 	return &v
 }
+
+func TestEmbedded(t *testing.T) {
+
+	type Location struct {
+		City string
+	}
+	type Geo struct {
+		Lat float64
+	}
+	type Place struct {
+		Name string
+		Location
+		*Geo
+		Other Location `rift:"other"`
+	}
+
+	tt := []struct {
+		Desc string
+		Opts []rift.Option
+		When []rift.Node
+		Bnds []rift.Change
+		Tree []string
+	}{
+		{
+			Desc: "nested",
+			When: []rift.Node{rift.Path("City", "Rome"), rift.Path("Location.City", "Oslo"), rift.Path("Lat", 1.5)},
+			Bnds: []rift.Change{
+				{Path: "Location.City", Type: "string", New: "Rome", Old: ""},
+				{Path: "Location.City", Type: "string", New: "Oslo", Old: "Rome"},
+				{Path: "Geo.Lat", Type: "float64", New: 1.5, Old: 0.0},
+			},
+			Tree: []string{"Name", "Location.City", "Geo.Lat", "Other.City"},
+		},
+		{
+			Desc: "flattened",
+			Opts: []rift.Option{rift.FlattenEmbedded()},
+			When: []rift.Node{rift.Path("City", "Rome"), rift.Path("Location.City", "Oslo"), rift.Path("Lat", 1.5)},
+			Bnds: []rift.Change{
+				{Path: "City", Type: "string", New: "Rome", Old: ""},
+				{Path: "City", Type: "string", New: "Oslo", Old: "Rome"},
+				{Path: "Lat", Type: "float64", New: 1.5, Old: 0.0},
+			},
+			Tree: []string{"Name", "City", "Lat", "Other.City"},
+		},
+		{
+			Desc: "flattened allocating the embedded pointer",
+			Opts: []rift.Option{rift.FlattenEmbedded(), rift.Structural()},
+			When: []rift.Node{rift.Path("Lat", 1.5)},
+			Bnds: []rift.Change{
+				{Op: rift.OpAlloc, Path: "Geo", Type: "Geo"},
+				{Path: "Lat", Type: "float64", New: 1.5, Old: 0.0},
+			},
+			Tree: []string{"Name", "City", "Lat", "Other.City"},
+		},
+		{
+			Desc: "nested allocating the embedded pointer",
+			Opts: []rift.Option{rift.Structural()},
+			When: []rift.Node{rift.Path("Lat", 1.5)},
+			Bnds: []rift.Change{
+				{Op: rift.OpAlloc, Path: "Geo", Type: "Geo"},
+				{Path: "Geo.Lat", Type: "float64", New: 1.5, Old: 0.0},
+			},
+			Tree: []string{"Name", "Location.City", "Geo.Lat", "Other.City"},
+		},
+	}
+
+	for _, tc := range tt {
+		var p Place
+		cs, err := rift.Apply(&p, tc.When, tc.Opts...)
+		assertEqual(t, nil, err, tc.Desc)
+		assertEqual(t, rift.ChangeSet(tc.Bnds), cs, tc.Desc)
+
+		var got, schema []string
+		for _, n := range rift.GetFlat(p, tc.Opts...) {
+			got = append(got, n.Path)
+		}
+		var leaves func(rift.Node)
+		leaves = func(n rift.Node) {
+			if n.Leaf {
+				schema = append(schema, n.Path)
+			}
+			for _, v := range n.Next {
+				leaves(v)
+			}
+		}
+		leaves(rift.Schema(reflect.TypeFor[Place](), tc.Opts...))
+		assertEqual(t, tc.Tree, got, tc.Desc)
+		assertEqual(t, tc.Tree, schema, tc.Desc)
+
+		a, _ := rift.GetPath(p, "City")
+		b, _ := rift.GetPath(p, "Location.City")
+		assertEqual(t, a, b, tc.Desc)
+	}
+
+	var p Place
+	_, err := rift.Apply(&p, []rift.Node{rift.Path("Lat", 1.5), rift.Path("Nope", 1)}, rift.FlattenEmbedded(), rift.Atomic())
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "flattened rollback")
+	assertEqual(t, Place{}, p, "flattened rollback")
+
+	cs, _ := rift.Apply(&p, []rift.Node{rift.Path("Lat", 1.5)}, rift.FlattenEmbedded(), rift.Structural())
+	_, err = rift.Apply(&p, cs.Invert().Nodes(), rift.FlattenEmbedded())
+	assertEqual(t, nil, err, "flattened invert")
+	assertEqual(t, Place{}, p, "flattened invert")
+}
+
+func TestFieldMatching(t *testing.T) {
+
+	type Address struct {
+		StreetName string `rift:"street,alias=street_name,alias=road"`
+		Number     int
+	}
+	type User struct {
+		Name    string
+		Address Address
+	}
+
+	tt := []struct {
+		Desc string
+		Opts []rift.Option
+		Path string
+		Give any
+		Then string
+		Err  bool
+	}{
+		{Desc: "go name", Path: "Address.StreetName", Then: "Address.StreetName"},
+		{Desc: "tag name", Path: "Address.street", Then: "Address.StreetName"},
+		{Desc: "alias", Path: "Address.street_name", Then: "Address.StreetName"},
+		{Desc: "second alias", Path: "Address.road", Then: "Address.StreetName"},
+		{Desc: "case sensitive", Path: "address.Number", Err: true},
+		{Desc: "ignore case", Opts: []rift.Option{rift.IgnoreCase()}, Path: "ADDRESS.number", Give: 10, Then: "Address.Number"},
+		{Desc: "ignore case of alias", Opts: []rift.Option{rift.IgnoreCase()}, Path: "address.STREET_NAME", Then: "Address.StreetName"},
+		{Desc: "ignore case keeps underscores", Opts: []rift.Option{rift.IgnoreCase()}, Path: "Address.Street_Name_", Err: true},
+		{Desc: "normalize case", Opts: []rift.Option{rift.NormalizeCase()}, Path: "address.streetname", Then: "Address.StreetName"},
+		{Desc: "normalize snake case", Opts: []rift.Option{rift.NormalizeCase()}, Path: "address.street-name", Then: "Address.StreetName"},
+	}
+
+	for _, tc := range tt {
+		if tc.Give == nil {
+			tc.Give = "Main"
+		}
+		var u User
+		c, err := rift.TrySetPath(&u, tc.Path, tc.Give, tc.Opts...)
+		if tc.Err {
+			assertEqual(t, true, err != nil, tc.Desc)
+			continue
+		}
+		assertEqual(t, nil, err, tc.Desc)
+		assertEqual(t, tc.Then, c.Path, tc.Desc)
+
+		v, err := rift.GetPath(u, tc.Path, tc.Opts...)
+		assertEqual(t, nil, err, tc.Desc)
+		assertEqual(t, c.New, v, tc.Desc)
+
+		_, err = rift.GetAs[any](u, tc.Path, tc.Opts...)
+		assertEqual(t, nil, err, tc.Desc)
+	}
+
+	n, err := rift.NodeFromJSON([]byte(`{"name":"John","address":{"street_name":"Main"}}`), User{}, rift.NormalizeCase())
+	assertEqual(t, nil, err)
+	var u User
+	cs := rift.Set(&u, n)
+	assertEqual(t, User{Name: "John", Address: Address{StreetName: "Main"}}, u)
+	assertEqual(t, []rift.Change{
+		{Path: "Name", Type: "string", New: "John", Old: ""},
+		{Path: "Address.StreetName", Type: "string", New: "Main", Old: ""},
+	}, cs)
+}
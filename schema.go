@@ -0,0 +1,126 @@
+package rift
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Wildcard segments of the templated paths returned by [Schema].
+const (
+	AnyIndex = "[]" // Any slice or array index.
+	AnyKey   = "*"  // Any map key.
+)
+
+// Schema returns a tree of all the addressable paths of the provided type.
+// Slice indexes are reported as [AnyIndex] and map keys as [AnyKey],
+// as in "Addresses.[].Street". Maps whose keys are not strings are leaves. Recursive types stop at the first repetition,
+// whose node refers back to the node it repeats. See [Node.Find].
+// See [FlattenEmbedded] for the options that apply.
+func Schema(t reflect.Type, opts ...Option) Node {
+	var out Node
+	newConfig(opts).schema(t, "", nil, &out)
+	return out
+}
+
+// SchemaFor returns the schema of type T.
+func SchemaFor[T any](opts ...Option) Node {
+	return Schema(reflect.TypeFor[T](), opts...)
+}
+
+func (c *config) schema(t reflect.Type, path string, seen []reflect.Type, out *Node) {
+	out.Path = path
+	if t == nil {
+		out.Type = reflect.Interface.String()
+		return
+	}
+	out.GoType = t.String()
+	t = derefType(t)
+	out.Type = t.Kind().String()
+	if i := slices.Index(seen, t); i >= 0 && t.Kind() == reflect.Struct {
+		out.Ref = len(seen) - i
+		return
+	}
+	seen = append(seen, t)
+	switch t.Kind() {
+	case reflect.Interface:
+	case reflect.Slice, reflect.Array:
+		n := Node{Name: AnyIndex}
+		c.schema(t.Elem(), joinPath(path, AnyIndex), seen, &n)
+		out.Next = append(out.Next, n)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			// Only string keys are addressable, so the map is set as a whole.
+			out.Leaf = true
+			break
+		}
+		n := Node{Name: AnyKey}
+		c.schema(t.Elem(), joinPath(path, AnyKey), seen, &n)
+		out.Next = append(out.Next, n)
+	case reflect.Struct:
+		for _, f := range c.structFields(t) {
+			n := Node{Name: f.Name, Tag: f.Tag}
+			c.schema(f.Type, joinPath(path, f.Name), seen, &n)
+			out.Next = append(out.Next, n)
+		}
+		out.Leaf = out.Next == nil
+	default:
+		out.Leaf = true
+	}
+}
+
+// Find returns the node at path, relative to n.
+// Wildcard nodes of a [Schema] match any index or key, and
+// an interface node without children matches any path under it.
+// The paths under a node repeating a recursive type are found in
+// the node it refers to, as long as it is under n.
+func (n Node) Find(path string) (Node, bool) {
+	return n.find(path, nil)
+}
+
+// find is like Find, with the ancestors of n in up.
+func (n Node) find(path string, up []Node) (Node, bool) {
+	if path == "" {
+		return n, true
+	}
+	if n.Ref > 0 {
+		if n.Ref > len(up) {
+			return Node{}, false
+		}
+		r := up[len(up)-n.Ref]
+		v, ok := r.find(path, up[:len(up)-n.Ref])
+		if !ok {
+			return Node{}, false
+		}
+		return v.moved(r.Path, n.Path), true
+	}
+	if n.Type == reflect.Interface.String() && n.Next == nil {
+		return n, true
+	}
+	seg, rest, _ := strings.Cut(path, ".")
+	for _, v := range n.Next {
+		if v.Name == seg || v.Name == AnyKey || (v.Name == AnyIndex && isNumber(seg)) {
+			return v.find(rest, append(up, n))
+		}
+	}
+	return Node{}, false
+}
+
+// moved returns a copy of n with the prefix from of its paths replaced by to.
+func (n Node) moved(from, to string) Node {
+	out := n
+	out.Path = joinPath(to, strings.TrimPrefix(strings.TrimPrefix(n.Path, from), "."))
+	out.Next = make([]Node, len(n.Next))
+	for i, v := range n.Next {
+		out.Next[i] = v.moved(from, to)
+	}
+	if n.Next == nil {
+		out.Next = nil
+	}
+	return out
+}
+
+func isNumber(s string) bool {
+	_, ok := getNumber(s)
+	return ok
+}
@@ -0,0 +1,115 @@
+package rift_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestSchema(t *testing.T) {
+
+	type Address struct {
+		Street string `json:"street"`
+		Number *int
+	}
+
+	type User struct {
+		Name      string
+		Addresses []Address
+		Meta      map[string]any
+		Parent    *User
+		private   int
+	}
+
+	exp := rift.Node{Type: "struct", GoType: "rift_test.User", Next: []rift.Node{
+		{Name: "Name", Path: "Name", Type: "string", GoType: "string", Leaf: true},
+		{Name: "Addresses", Path: "Addresses", Type: "slice", GoType: "[]rift_test.Address", Next: []rift.Node{
+			{Name: "[]", Path: "Addresses.[]", Type: "struct", GoType: "rift_test.Address", Next: []rift.Node{
+				{Name: "Street", Path: "Addresses.[].Street", Type: "string", GoType: "string", Tag: `json:"street"`, Leaf: true},
+				{Name: "Number", Path: "Addresses.[].Number", Type: "int", GoType: "*int", Leaf: true},
+			}},
+		}},
+		{Name: "Meta", Path: "Meta", Type: "map", GoType: "map[string]interface {}", Next: []rift.Node{
+			{Name: "*", Path: "Meta.*", Type: "interface", GoType: "interface {}"},
+		}},
+		{Name: "Parent", Path: "Parent", Type: "struct", GoType: "*rift_test.User", Ref: 1},
+	}}
+
+	s := rift.SchemaFor[User]()
+	assertEqual(t, exp, s, "schema")
+	assertEqual(t, exp.Next, rift.Schema(reflect.TypeFor[*User]()).Next, "pointer root")
+
+	for _, tc := range []struct {
+		Path string
+		Then string
+		Ok   bool
+	}{
+		{"Name", "Name", true},
+		{"Addresses.3.Street", "Addresses.[].Street", true},
+		{"Addresses.x.Street", "", false},
+		{"Meta.a.b.c", "Meta.*", true},
+		{"Nope", "", false},
+		{"Name.Nope", "", false},
+		{"Parent.Parent.Addresses.0.Street", "Parent.Parent.Addresses.[].Street", true},
+		{"Parent.Nope", "", false},
+	} {
+		n, ok := s.Find(tc.Path)
+		assertEqual(t, tc.Ok, ok, tc.Path)
+		assertEqual(t, tc.Then, n.Path, tc.Path)
+	}
+}
+
+func TestSchemaRecursive(t *testing.T) {
+
+	type Rec struct {
+		Name string
+		Kids []Rec
+	}
+
+	s := rift.SchemaFor[Rec]()
+
+	n, ok := s.Find("Kids")
+	assertEqual(t, rift.Node{Name: "Kids", Path: "Kids", Type: "slice", GoType: "[]rift_test.Rec", Next: []rift.Node{
+		{Name: "[]", Path: "Kids.[]", Type: "struct", GoType: "rift_test.Rec", Ref: 2},
+	}}, n, "repetition refers back")
+	assertEqual(t, true, ok, "repetition refers back")
+
+	n, ok = s.Find("Kids.0.Kids.0.Name")
+	assertEqual(t, rift.Node{Name: "Name", Path: "Kids.[].Kids.[].Name", Type: "string", GoType: "string", Leaf: true}, n, "path under the repetition")
+	assertEqual(t, true, ok, "path under the repetition")
+
+	n, ok = s.Find("Kids.0.Kids")
+	assertEqual(t, "Kids.[].Kids", n.Path, "node under the repetition")
+	assertEqual(t, "Kids.[].Kids.[]", n.Next[0].Path, "node under the repetition")
+
+	_, ok = s.Find("Kids.0.Nope")
+	assertEqual(t, false, ok, "unknown path under the repetition")
+
+	_, ok = n.Next[0].Find("Name")
+	assertEqual(t, false, ok, "repeated node outside the subtree")
+}
+
+func TestSchemaMapKeys(t *testing.T) {
+
+	type Key string
+	type Maps struct {
+		ByID   map[int]string
+		ByName map[Key]string
+	}
+
+	s := rift.SchemaFor[Maps]()
+	assertEqual(t, rift.Node{Type: "struct", GoType: "rift_test.Maps", Next: []rift.Node{
+		{Name: "ByID", Path: "ByID", Type: "map", GoType: "map[int]string", Leaf: true},
+		{Name: "ByName", Path: "ByName", Type: "map", GoType: "map[rift_test.Key]string", Next: []rift.Node{
+			{Name: "*", Path: "ByName.*", Type: "string", GoType: "string", Leaf: true},
+		}},
+	}}, s, "only string keys are addressable")
+
+	_, ok := s.Find("ByID.1")
+	assertEqual(t, false, ok, "int key")
+
+	j := rift.JSONSchema(reflect.TypeFor[Maps]())
+	props := j["$defs"].(map[string]any)["rift_test.Maps"].(map[string]any)["properties"].(map[string]any)
+	assertEqual(t, map[string]any{"type": "object"}, props["ByID"], "json schema")
+}
@@ -0,0 +1,87 @@
+package rift
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogValue implements [slog.LogValuer], as a group of the op, path,
+// type and values of the change. The changes of [Redacted] values
+// report whether they changed instead of the values.
+func (c Change) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("op", c.Op.String()),
+		slog.String("path", c.Path),
+		slog.String("type", c.Type),
+	}
+	_, oldRedacted := c.Old.(Redacted)
+	_, newRedacted := c.New.(Redacted)
+	if oldRedacted || newRedacted {
+		attrs = append(attrs, slog.Bool("changed", c.Old != c.New))
+	} else {
+		attrs = append(attrs, slog.Any("old", c.Old), slog.Any("new", c.New))
+	}
+	if c.Noop {
+		attrs = append(attrs, slog.Bool("noop", true))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [slog.LogValuer], as a group of the changes
+// keyed by their paths. Repeated paths are reported once per change.
+func (cs ChangeSet) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(cs))
+	for i, c := range cs {
+		attrs[i] = slog.Any(c.Path, c)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [slog.LogValuer], as nested groups of the children
+// keyed by their names, down to the Data of the leaves.
+// The Data of [Redacted] values is logged as [REDACTED].
+func (n Node) LogValue() slog.Value {
+	if len(n.Next) == 0 {
+		return slog.AnyValue(n.Data)
+	}
+	attrs := make([]slog.Attr, len(n.Next))
+	for i, v := range n.Next {
+		attrs[i] = slog.Any(nodeName(v), v)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [slog.LogValuer].
+func (r Redacted) LogValue() slog.Value {
+	return slog.StringValue(r.String())
+}
+
+// Audit logs a record of each batch of [Apply] and [Set] to logger,
+// with the actor and reason of the batch and its changes, or its error.
+// Use it with [Redact] to keep the sensitive values out of the log.
+//
+//	rift.Apply(&order, nodes, rift.Audit(logger, user.ID, "PATCH /orders/1"))
+func Audit(logger *slog.Logger, actor, reason string) Option {
+	return func(c *config) {
+		c.audit = &auditor{logger: logger, actor: actor, reason: reason}
+	}
+}
+
+type auditor struct {
+	logger *slog.Logger
+	actor  string
+	reason string
+}
+
+func (a *auditor) log(cs ChangeSet, err error) {
+	attrs := []slog.Attr{
+		slog.String("actor", a.actor),
+		slog.String("reason", a.reason),
+		slog.Any("changes", cs),
+	}
+	if err != nil {
+		a.logger.LogAttrs(context.Background(), slog.LevelError, "rift: batch failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	a.logger.LogAttrs(context.Background(), slog.LevelInfo, "rift: batch applied", attrs...)
+}
@@ -0,0 +1,50 @@
+package rift_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestLogValue(t *testing.T) {
+
+	type User struct {
+		Name     string
+		Password string `rift:",secret"`
+		Tags     []string
+	}
+
+	var b bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	give := User{Name: "John", Password: "a"}
+
+	_, err := rift.Apply(&give, []rift.Node{
+		rift.Path("Name", "Luke"),
+		rift.Path("Password", "b"),
+	}, rift.Redact(), rift.Audit(logger, "admin", "rename"))
+	assertEqual(t, nil, err, "apply")
+
+	_, err = rift.Apply(&give, []rift.Node{rift.Path("Name", 1)}, rift.Audit(logger, "admin", "typo"))
+	assertEqual(t, true, err != nil, "failed apply")
+
+	logger.Info("tree", "user", rift.Get(User{Name: "Leia", Tags: []string{"a"}}))
+	logger.Info("redacted tree", "user", rift.Get(give, rift.Redact()))
+
+	assertEqual(t, `{"level":"INFO","msg":"rift: batch applied","actor":"admin","reason":"rename","changes":{`+
+		`"Name":{"op":"set","path":"Name","type":"string","old":"John","new":"Luke"},`+
+		`"Password":{"op":"set","path":"Password","type":"string","changed":true}}}
+{"level":"ERROR","msg":"rift: batch failed","actor":"admin","reason":"typo","error":"rift: Name: type mismatch"}
+{"level":"INFO","msg":"tree","user":{"Name":"Leia","Password":"","Tags":{"0":"a"}}}
+{"level":"INFO","msg":"redacted tree","user":{"Name":"Luke","Password":"[REDACTED]","Tags":null}}
+`, b.String())
+}
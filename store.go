@@ -0,0 +1,173 @@
+package rift
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds a value of type T that goroutines read and change concurrently.
+//
+// Changes are serialized and made to a copy of the value, which then
+// replaces it, so readers get consistent snapshots without waiting.
+// A batch that fails leaves the value as it was.
+// Snapshots, and the values read from them, must not be changed.
+//
+// Subscribers are notified of the changes of each batch in the order the
+// batches are stored, before the batch call returns and before the next
+// batch starts, and in the order they subscribed.
+type Store[T any] struct {
+	mu   sync.Mutex // Serializes the changes.
+	cur  atomic.Pointer[T]
+	smu  sync.Mutex // Guards subs, apart from mu so that subscribers can cancel.
+	subs []*subscription
+}
+
+type subscription struct {
+	pattern  string
+	fn       func(ChangeSet)
+	canceled atomic.Bool
+}
+
+// NewStore returns a store holding a copy of v.
+func NewStore[T any](v T) *Store[T] {
+	s := &Store[T]{}
+	v = cloneOf(v)
+	s.cur.Store(&v)
+	return s
+}
+
+// Load returns a snapshot of the value.
+func (s *Store[T]) Load() T {
+	return *s.cur.Load()
+}
+
+// Get returns the tree of a snapshot of the value, as [Get] does.
+func (s *Store[T]) Get(opts ...Option) Node {
+	return Get(s.Load(), opts...)
+}
+
+// GetPath returns the value at path of a snapshot, as [GetPath] does.
+func (s *Store[T]) GetPath(path string, opts ...Option) (any, error) {
+	return GetPath(s.Load(), path, opts...)
+}
+
+// SetPath sets a value at path, as [TrySetPath] does.
+func (s *Store[T]) SetPath(path string, val any, opts ...Option) (Change, error) {
+	var c Change
+	err := s.update(func(v *T) (ChangeSet, error) {
+		var err error
+		c, err = TrySetPath(v, path, val, opts...)
+		return ChangeSet{c}, err
+	})
+	return c, err
+}
+
+// Set sets the values of a node, as [Set] does,
+// but returns an error instead of panicking.
+func (s *Store[T]) Set(n Node, opts ...Option) (ChangeSet, error) {
+	c := newConfig(opts)
+	c.lenient = true
+	return s.apply(c, []Node{n})
+}
+
+// SetMany sets the values of the nodes, as [SetMany] does,
+// but returns an error instead of panicking.
+func (s *Store[T]) SetMany(ns ...Node) (ChangeSet, error) {
+	c := newConfig(nil)
+	c.lenient = true
+	return s.apply(c, ns)
+}
+
+// Apply sets the values of the nodes, as [Apply] does.
+func (s *Store[T]) Apply(ns []Node, opts ...Option) (ChangeSet, error) {
+	return s.apply(newConfig(opts), ns)
+}
+
+func (s *Store[T]) apply(c *config, ns []Node) (ChangeSet, error) {
+	var cs ChangeSet
+	err := s.update(func(v *T) (ChangeSet, error) {
+		var err error
+		cs, err = c.apply(v, ns)
+		return cs, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Subscribe calls fn with the changes of each batch whose paths match
+// pattern, as [ChangeSet.Match] does, as in "Limits.**".
+// Batches without matching changes are not notified.
+// fn is called by the goroutine changing the store, which waits for it,
+// so fn can read the store and cancel subscriptions, its own included,
+// but must not change the store.
+// The returned function cancels the subscription.
+func (s *Store[T]) Subscribe(pattern string, fn func(ChangeSet)) (cancel func()) {
+	sub := &subscription{pattern: pattern, fn: fn}
+	s.smu.Lock()
+	s.subs = append(s.subs, sub)
+	s.smu.Unlock()
+	return func() {
+		sub.canceled.Store(true)
+		s.smu.Lock()
+		s.subs = slices.DeleteFunc(s.subs, func(v *subscription) bool { return v == sub })
+		s.smu.Unlock()
+	}
+}
+
+// Watch is like [Store.Subscribe], but sends the changes to the returned
+// channel, buffered by size, which is closed by cancel.
+// Sending waits for room in the channel, holding up the store changes,
+// so the receiver must keep up or cancel.
+func (s *Store[T]) Watch(pattern string, size int) (<-chan ChangeSet, func()) {
+	ch := make(chan ChangeSet, size)
+	done := make(chan struct{})
+	var mu sync.Mutex // Keeps ch from being closed while sending to it.
+	closed := false
+	unsubscribe := s.Subscribe(pattern, func(cs ChangeSet) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- cs:
+		case <-done:
+		}
+	})
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			close(done) // Ends a send in flight.
+			unsubscribe()
+			mu.Lock()
+			closed = true
+			close(ch)
+			mu.Unlock()
+		})
+	}
+}
+
+// update calls fn with a copy of the value and stores it, unless fn fails,
+// and notifies the subscribers of the changes.
+func (s *Store[T]) update(fn func(*T) (ChangeSet, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := cloneOf(s.Load())
+	cs, err := fn(&v)
+	if err != nil {
+		return err
+	}
+	s.cur.Store(&v)
+	s.smu.Lock()
+	subs := slices.Clone(s.subs)
+	s.smu.Unlock()
+	for _, sub := range subs {
+		if m := cs.Match(sub.pattern); len(m) > 0 && !sub.canceled.Load() {
+			sub.fn(m)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package rift_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestStore(t *testing.T) {
+
+	give := TestData{Int: 1, Slice: []TestData{{Int: 2}}, Map: map[string]any{"a": 1}}
+	s := rift.NewStore(give)
+
+	snap := s.Load()
+
+	cs, err := s.SetMany(rift.Path("Slice.0.Int", 3), rift.Path("Map.a", 4), rift.Path("IntPtr", 5))
+	assertEqual(t, nil, err, "set many")
+	assertEqual(t, rift.ChangeSet{
+		{Path: "Slice.0.Int", Type: "int", New: 3, Old: 2},
+		{Path: "Map.a", Type: "int", New: 4, Old: 1},
+		{Path: "IntPtr", Type: "int", New: 5, Old: nil},
+	}, cs, "set many")
+
+	assertEqual(t, TestData{Int: 1, Slice: []TestData{{Int: 2}}, Map: map[string]any{"a": 1}}, snap, "snapshot kept")
+	assertEqual(t, TestData{Int: 1, Slice: []TestData{{Int: 2}}, Map: map[string]any{"a": 1}}, give, "source kept")
+	assertEqual(t, TestData{Int: 1, Slice: []TestData{{Int: 3}}, Map: map[string]any{"a": 4}, IntPtr: ptr(5)}, s.Load(), "stored")
+
+	_, err = s.SetMany(rift.Path("Int", 6), rift.Path("String", 7))
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "failed batch")
+	v, _ := s.GetPath("Int")
+	assertEqual(t, 1, v, "failed batch discarded")
+
+	c, err := s.SetPath("String", "x")
+	assertEqual(t, nil, err, "set path")
+	assertEqual(t, rift.Change{Path: "String", Type: "string", New: "x", Old: ""}, c, "set path")
+
+	cs, err = s.SetMany(rift.Path("Int", 2), rift.Path("Nope", 3))
+	assertEqual(t, nil, err, "set many ignores unknown paths")
+	assertEqual(t, 2, s.Load().Int, "set many ignores unknown paths")
+
+	_, err = s.Set(rift.Path("Nope", 3))
+	assertEqual(t, nil, err, "set ignores unknown paths")
+
+	_, err = s.Apply([]rift.Node{rift.Path("Nope", 3)})
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "apply reports unknown paths")
+}
+
+func TestStoreConcurrent(t *testing.T) {
+
+	s := rift.NewStore(TestData{})
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				k := strconv.Itoa(i*10 + j)
+				if _, err := s.Apply([]rift.Node{rift.Path("Map."+k, j), rift.Path("Int", 0)}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				for _, n := range s.Get().Next {
+					_ = n.Data
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assertEqual(t, 100, len(s.Load().Map))
+}
+
+func TestStoreSubscribe(t *testing.T) {
+
+	type Limits struct {
+		Rate  int
+		Burst int
+	}
+	type Config struct {
+		Name   string
+		Limits Limits
+	}
+
+	s := rift.NewStore(Config{})
+
+	var got []string
+	s.Subscribe("Limits.**", func(cs rift.ChangeSet) {
+		for _, c := range cs {
+			got = append(got, "a:"+c.Path)
+		}
+	})
+	cancel := s.Subscribe("*", func(cs rift.ChangeSet) {
+		for _, c := range cs {
+			got = append(got, "b:"+c.Path)
+		}
+	})
+
+	s.SetMany(rift.Path("Limits.Rate", 1), rift.Path("Name", "x"), rift.Path("Limits.Burst", 2))
+	s.SetPath("Limits", Limits{})
+	s.SetMany(rift.Path("Name", "y"), rift.Path("Limits.Rate", "fail"))
+	cancel()
+	s.SetPath("Name", "z")
+
+	assertEqual(t, []string{
+		"a:Limits.Rate", "a:Limits.Burst", "b:Name", // First batch, by subscriber.
+		"a:Limits", "b:Limits",
+	}, got)
+
+	var once []rift.ChangeSet
+	var cancelOnce func()
+	cancelOnce = s.Subscribe("**", func(cs rift.ChangeSet) {
+		once = append(once, cs)
+		cancelOnce()
+	})
+	s.Subscribe("**", func(rift.ChangeSet) {
+		cancel() // Canceled already.
+	})
+	s.SetPath("Name", "a")
+	s.SetPath("Name", "b")
+
+	assertEqual(t, []rift.ChangeSet{{{Path: "Name", Type: "string", New: "a", Old: "z"}}}, once, "canceled by itself")
+}
+
+func TestStoreWatch(t *testing.T) {
+
+	s := rift.NewStore(TestData{})
+
+	ch, cancel := s.Watch("Int", 0)
+
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				s.Apply([]rift.Node{rift.Path("Map."+strconv.Itoa(i*10+j), j)}, rift.Version("Int"))
+			}
+		}()
+	}
+
+	for i := range 50 {
+		cs := <-ch
+		assertEqual(t, rift.ChangeSet{{Path: "Int", Type: "int", New: i + 1, Old: i}}, cs, "batches in order")
+	}
+	wg.Wait()
+
+	cancel()
+	_, ok := <-ch
+	assertEqual(t, false, ok, "closed")
+	s.SetPath("Int", 0)
+}
+
+func TestStoreWatchCancel(t *testing.T) {
+
+	s := rift.NewStore(TestData{})
+
+	ch, cancel := s.Watch("**", 0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			s.SetPath("Int", i+1)
+		}
+	}()
+
+	<-ch
+	cancel() // While the store sends the next batches.
+	wg.Wait()
+
+	for range ch {
+	}
+	assertEqual(t, 100, s.Load().Int, "changes go on")
+}
@@ -0,0 +1,54 @@
+package rift
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tag is a parsed rift struct tag, in the form
+// `rift:"name,option,option=value"`.
+type tag struct {
+	name string
+	opts []string
+}
+
+func parseTag(t reflect.StructTag) tag {
+	s, ok := t.Lookup("rift")
+	if !ok {
+		return tag{}
+	}
+	name, opts, _ := strings.Cut(s, ",")
+	out := tag{name: name}
+	if opts != "" {
+		out.opts = strings.Split(opts, ",")
+	}
+	return out
+}
+
+// has reports whether the tag has the option.
+func (t tag) has(opt string) bool {
+	_, ok := t.get(opt)
+	return ok
+}
+
+// get returns the value of an option.
+func (t tag) get(opt string) (string, bool) {
+	for _, o := range t.opts {
+		k, v, _ := strings.Cut(o, "=")
+		if k == opt {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// all returns the values of an option that may be repeated.
+func (t tag) all(opt string) []string {
+	var out []string
+	for _, o := range t.opts {
+		if k, v, _ := strings.Cut(o, "="); k == opt {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package rift
+
+import (
+	"reflect"
+)
+
+// GetAs returns the value at path as T.
+// The path is validated against the static type of v
+// and T before the value is read.
+// See [IgnoreCase] for the options that apply.
+func GetAs[T any](v any, path string, opts ...Option) (T, error) {
+	var zero T
+	t := reflect.TypeFor[T]()
+	c := newConfig(opts)
+	p, err := c.compile(reflect.TypeOf(v), path)
+	if err != nil {
+		return zero, err
+	}
+	if !p.canGet(t) {
+		return zero, &PathError{Path: path, Err: ErrType}
+	}
+	r, err := p.get(c, reflect.ValueOf(v), path)
+	if err != nil {
+		return zero, pathError(path, err)
+	}
+	out, ok := valueAs[T](r)
+	if !ok {
+		return zero, &PathError{Path: path, Err: ErrType}
+	}
+	return out, nil
+}
+
+// SetAs sets a value of type T to dst based on the provided path.
+// The path is validated against the static type of dst
+// and T before the value is set.
+// See [IgnoreCase] for the options that apply.
+// [Redact] does not apply, as typed changes keep the values of type T;
+// use [TrySetPath] to get them redacted.
+func SetAs[T any](dst any, path string, val T, opts ...Option) (TypedChange[T], error) {
+	c := newConfig(opts)
+	p, err := c.compile(reflect.TypeOf(dst), path)
+	if err != nil {
+		return TypedChange[T]{}, err
+	}
+	if !p.canSet(reflect.TypeFor[T]()) {
+		return TypedChange[T]{}, &PathError{Path: path, Err: ErrType}
+	}
+	if err := c.validate(dst, []Node{Path(path, val)}); err != nil {
+		return TypedChange[T]{}, err
+	}
+	v := reflect.ValueOf(val)
+	s := setter{config: c}
+	old, err := s.set(reflect.ValueOf(dst), v, path)
+	if err != nil {
+		return TypedChange[T]{}, pathError(path, err)
+	}
+	o, _ := old.(T)
+	return TypedChange[T]{Path: s.canonical(), Type: getType(v), New: val, Old: o}, nil
+}
+
+// TypedChange represents a change of a value of type T.
+type TypedChange[T any] struct {
+	Path string
+	Type string
+	New  T // New value set.
+	Old  T // Old value before set.
+}
+
+// Change returns c as an untyped change.
+func (c TypedChange[T]) Change() Change {
+	return Change{Path: c.Path, Type: c.Type, New: c.New, Old: c.Old}
+}
+
+func valueAs[T any](v reflect.Value) (T, bool) {
+	var zero T
+	t := reflect.TypeFor[T]()
+	for {
+		if !v.IsValid() {
+			return zero, true
+		}
+		if v.Type() == t {
+			return v.Interface().(T), true
+		}
+		if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			v = v.Elem()
+			continue
+		}
+		if v.Type().AssignableTo(t) {
+			return v.Interface().(T), true
+		}
+		return zero, false
+	}
+}
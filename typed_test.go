@@ -0,0 +1,145 @@
+package rift_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestGetAs(t *testing.T) {
+
+	give := TestData{
+		Int:    11,
+		IntPtr: ptr(22),
+		Slice:  []TestData{{String: "A"}},
+		Struct: &TestData{Int: 33},
+		Any:    map[string]any{"Int": 44},
+		Map:    map[string]any{"Arr": []any{55}},
+	}
+
+	i, err := rift.GetAs[int](give, "Int")
+	assertEqual(t, 11, i, "int field")
+	assertEqual(t, nil, err, "int field")
+
+	i, err = rift.GetAs[int](&give, "IntPtr")
+	assertEqual(t, 22, i, "pointer field as its element")
+	assertEqual(t, nil, err, "pointer field as its element")
+
+	p, err := rift.GetAs[*int](&give, "IntPtr")
+	assertEqual(t, give.IntPtr, p, "pointer field as pointer")
+	assertEqual(t, nil, err, "pointer field as pointer")
+
+	s, err := rift.GetAs[string](give, "Slice.0.String")
+	assertEqual(t, "A", s, "slice element field")
+	assertEqual(t, nil, err, "slice element field")
+
+	i, err = rift.GetAs[int](give, "Struct.Int")
+	assertEqual(t, 33, i, "struct pointer field")
+	assertEqual(t, nil, err, "struct pointer field")
+
+	i, err = rift.GetAs[int](give, "Any.Int")
+	assertEqual(t, 44, i, "dynamic field")
+	assertEqual(t, nil, err, "dynamic field")
+
+	i, err = rift.GetAs[int](give, "Map.Arr.0")
+	assertEqual(t, 55, i, "dynamic slice element")
+	assertEqual(t, nil, err, "dynamic slice element")
+
+	_, err = rift.GetAs[string](give, "Int")
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "static type mismatch")
+
+	_, err = rift.GetAs[string](give, "Any.Int")
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "dynamic type mismatch")
+
+	_, err = rift.GetAs[int](give, "Nope")
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "unknown field")
+
+	_, err = rift.GetAs[int](give, "Slice.1.Int")
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "index out of range")
+
+	_, err = rift.GetAs[int](give, "Slice.x.Int")
+	assertEqual(t, true, errors.Is(err, rift.ErrIndex), "invalid index")
+
+	_, err = rift.GetAs[string](give, "Slice.[].String")
+	assertEqual(t, true, errors.Is(err, rift.ErrIndex), "index template is not an index")
+}
+
+func TestSetAs(t *testing.T) {
+
+	var give TestData
+
+	c, err := rift.SetAs(&give, "Int", 3)
+	assertEqual(t, rift.TypedChange[int]{Path: "Int", Type: "int", New: 3, Old: 0}, c, "int field")
+	assertEqual(t, nil, err, "int field")
+
+	c, err = rift.SetAs(&give, "IntPtr", 4)
+	assertEqual(t, rift.TypedChange[int]{Path: "IntPtr", Type: "int", New: 4, Old: 0}, c, "pointer field")
+	assertEqual(t, nil, err, "pointer field")
+
+	c, err = rift.SetAs(&give, "IntPtr", 5)
+	assertEqual(t, rift.TypedChange[int]{Path: "IntPtr", Type: "int", New: 5, Old: 4}, c, "pointer field old value")
+	assertEqual(t, nil, err, "pointer field old value")
+
+	s, err := rift.SetAs(&give, "Slice.1.String", "B")
+	assertEqual(t, rift.TypedChange[string]{Path: "Slice.1.String", Type: "string", New: "B", Old: ""}, s, "slice element field")
+	assertEqual(t, nil, err, "slice element field")
+
+	_, err = rift.SetAs(&give, "Any.Int", 6)
+	assertEqual(t, nil, err, "dynamic field")
+
+	assertEqual(t, TestData{
+		Int:    3,
+		IntPtr: ptr(5),
+		Slice:  []TestData{{}, {String: "B"}},
+		Any:    map[string]any{"Int": 6},
+	}, give, "result")
+
+	_, err = rift.SetAs(&give, "Int", "3")
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "type mismatch")
+
+	_, err = rift.SetAs(&give, "Nope", 3)
+	assertEqual(t, true, errors.Is(err, rift.ErrNotFound), "unknown field")
+
+	assertEqual(t, 3, give.Int, "failed writes must not change the value")
+}
+
+func TestSetPointer(t *testing.T) {
+
+	type Addr struct {
+		Street string
+	}
+	type Person struct {
+		P  *Addr
+		IP *int
+	}
+
+	var give Person
+
+	a := &Addr{Street: "Main"}
+	c, err := rift.SetAs(&give, "P", a)
+	assertEqual(t, nil, err, "typed pointer")
+	assertEqual(t, rift.TypedChange[*Addr]{Path: "P", Type: "", New: a, Old: nil}, c, "typed pointer")
+	assertEqual(t, a, give.P, "pointer is assigned itself")
+
+	b := &Addr{Street: "Side"}
+	c, err = rift.SetAs(&give, "P", b)
+	assertEqual(t, nil, err, "typed pointer replaced")
+	assertEqual(t, rift.TypedChange[*Addr]{Path: "P", Type: "", New: b, Old: a}, c, "typed pointer replaced")
+
+	chg := rift.SetPath(&give, "IP", ptr(5))
+	assertEqual(t, rift.Change{Path: "IP", Type: "", New: ptr(5), Old: nil}, chg, "untyped pointer")
+	assertEqual(t, 5, *give.IP, "untyped pointer")
+
+	chg = rift.SetPath(&give, "IP", 6)
+	assertEqual(t, rift.Change{Path: "IP", Type: "int", New: 6, Old: 5}, chg, "pointer element")
+
+	give = Person{}
+	_, err = rift.TrySetPath(&give, "P.Street", 1)
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "failed set under a nil pointer")
+	assertEqual(t, Person{}, give, "failed set leaves the pointer nil")
+
+	_, err = rift.TrySetPath(&give, "IP", "x")
+	assertEqual(t, true, errors.Is(err, rift.ErrType), "failed set of a nil pointer")
+	assertEqual(t, Person{}, give, "failed set leaves the pointer nil")
+}
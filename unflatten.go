@@ -0,0 +1,68 @@
+package rift
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Unflatten returns the tree of the provided flat nodes, the inverse of [GetFlat].
+// The nodes in between are named after the path segments and typed as
+// slice when all their children are indexes, or as map otherwise.
+// A path repeated replaces the previous one.
+func Unflatten(ns []Node) Node {
+	var root Node
+	for _, n := range ns {
+		insert(&root, n)
+	}
+	infer(&root)
+	return root
+}
+
+// ChangesToNode returns the tree of the provided changes,
+// with the New value of each change as Data.
+func ChangesToNode(cs []Change) Node {
+	ns := make([]Node, len(cs))
+	for i, c := range cs {
+		v := c.New
+		if c.Op == OpDelete {
+			v = c.Old
+		}
+		ns[i] = Node{Op: c.Op, Path: c.Path, Type: getKind(reflect.ValueOf(v)), Data: c.New}
+	}
+	return Unflatten(ns)
+}
+
+func insert(root *Node, n Node) {
+	cur := root
+	for seg := range segments(n.Path) {
+		i := slices.IndexFunc(cur.Next, func(c Node) bool { return c.Name == seg })
+		if i < 0 {
+			cur.Next = append(cur.Next, Node{Name: seg, Path: joinPath(cur.Path, seg)})
+			i = len(cur.Next) - 1
+		}
+		cur = &cur.Next[i]
+	}
+	n.Name, n.Path, n.Next = cur.Name, cur.Path, cur.Next
+	*cur = n
+}
+
+func infer(n *Node) {
+	if len(n.Next) == 0 {
+		return
+	}
+	for i := range n.Next {
+		infer(&n.Next[i])
+	}
+	if n.Type != "" {
+		return
+	}
+	n.Type = reflect.Map.String()
+	if !slices.ContainsFunc(n.Next, func(c Node) bool { return !isNumber(c.Name) }) {
+		n.Type = reflect.Slice.String()
+		slices.SortStableFunc(n.Next, func(a, b Node) int {
+			x, _ := getNumber(a.Name)
+			y, _ := getNumber(b.Name)
+			return x - y
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package rift_test
+
+import (
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestUnflatten(t *testing.T) {
+
+	give := map[string]any{"Arr": []any{1, map[string]any{"a": 2}}}
+
+	assertEqual(t, rift.Get(give), rift.Unflatten(rift.GetFlat(give)), "inverse of GetFlat")
+
+	assertEqual(t, rift.Node{Type: "map", Next: []rift.Node{
+		{Name: "Slice", Path: "Slice", Type: "slice", Next: []rift.Node{
+			{Name: "0", Path: "Slice.0", Type: "int", Data: 3},
+			{Name: "2", Path: "Slice.2", Type: "int", Data: 4},
+		}},
+		{Name: "Int", Path: "Int", Type: "int", Data: 6},
+	}}, rift.Unflatten([]rift.Node{
+		{Path: "Slice.2", Type: "int", Data: 4},
+		{Path: "Int", Type: "int", Data: 5},
+		{Path: "Slice.0", Type: "int", Data: 3},
+		{Path: "Int", Type: "int", Data: 6},
+	}), "sorted indexes and repeated paths")
+}
+
+func TestChangesToNode(t *testing.T) {
+
+	give := &TestData{Slice: []TestData{{}, {}}, Map: map[string]any{"a": 1}}
+
+	cs := rift.SetMany(give,
+		rift.Path("Int", 2),
+		rift.Path("Slice.0.String", "A"),
+		rift.Path("Map.b", 3),
+	)
+	cs = append(cs, rift.Set(give, rift.Node{Path: "Slice", Next: []rift.Node{{Path: "Slice.0.Int", Data: 1}}}, rift.Replace())...)
+
+	n := rift.ChangesToNode(cs)
+	assertEqual(t, rift.Node{Type: "map", Next: []rift.Node{
+		{Name: "Int", Path: "Int", Type: "int", Data: 2},
+		{Name: "Slice", Path: "Slice", Type: "slice", Next: []rift.Node{
+			{Name: "0", Path: "Slice.0", Type: "map", Next: []rift.Node{
+				{Name: "String", Path: "Slice.0.String", Type: "string", Data: "A"},
+				{Name: "Int", Path: "Slice.0.Int", Type: "int", Data: 1},
+			}},
+			{Op: rift.OpDelete, Name: "1", Path: "Slice.1", Type: "struct"},
+		}},
+		{Name: "Map", Path: "Map", Type: "map", Next: []rift.Node{
+			{Name: "b", Path: "Map.b", Type: "int", Data: 3},
+		}},
+	}}, n, "tree of changes")
+
+	var then TestData
+	rift.SetMany(&then, rift.Path("Slice.1.Int", 9))
+	rift.Set(&then, n)
+	assertEqual(t, TestData{
+		Int:   2,
+		Slice: []TestData{{Int: 1, String: "A"}},
+		Map:   map[string]any{"b": 3},
+	}, then, "replayed changes")
+}
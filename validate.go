@@ -0,0 +1,240 @@
+package rift
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalid is wrapped by the errors of the rules broken by a value.
+var ErrInvalid = errors.New("invalid value")
+
+// ValidationError reports the rules broken by the values set in a batch,
+// keyed by the path of each value.
+//
+// The rules are options of the rift tag of the field a value is set to:
+//
+//	required     the value is not the zero value
+//	min=n        numbers are at least n, and strings, slices and maps have at least n elements
+//	max=n        numbers are at most n, and strings, slices and maps have at most n elements
+//	len=n        strings, slices and maps have n elements
+//	oneof=a b c  the value, formatted with fmt, is one of the space-separated values
+//	regexp=re    strings match re, which cannot contain commas
+//
+// When a batch breaks any rule, no value is set.
+type ValidationError map[string][]error
+
+func (e ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("rift: invalid values:")
+	for i, p := range e.paths() {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		b.WriteString(" " + p + ":")
+		for j, err := range e[p] {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(" " + err.Error())
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the paths in order.
+func (e ValidationError) Unwrap() []error {
+	var out []error
+	for _, p := range e.paths() {
+		out = append(out, e[p]...)
+	}
+	return out
+}
+
+func (e ValidationError) paths() []string {
+	out := make([]string, 0, len(e))
+	for p := range e {
+		out = append(out, p)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// RuleError reports a rule broken by a value.
+type RuleError struct {
+	Rule  string
+	Param string
+}
+
+func (e *RuleError) Error() string {
+	if e.Param == "" {
+		return "breaks " + e.Rule
+	}
+	return "breaks " + e.Rule + "=" + e.Param
+}
+
+func (e *RuleError) Unwrap() error {
+	return ErrInvalid
+}
+
+// validate checks the leaves of ns against the rules of their fields.
+func (c *config) validate(dst any, ns []Node) error {
+	out := ValidationError{}
+	for _, n := range ns {
+		walk(n, func(n Node) {
+			if n.Op != OpSet || len(n.Next) > 0 {
+				return
+			}
+			fs, ok := c.fieldsAt(reflect.ValueOf(dst), n.Path)
+			if !ok {
+				return
+			}
+			if errs := checkRules(parseTag(fs[len(fs)-1].Tag), reflect.ValueOf(n.Data)); errs != nil {
+				out[n.Path] = append(out[n.Path], errs...)
+			}
+		})
+	}
+	if len(out) > 0 {
+		return out
+	}
+	return nil
+}
+
+// fieldsAt returns the struct fields along path, and whether path ends at
+// the last of them, following the values of v where they exist and their
+// types elsewhere.
+func (c *config) fieldsAt(v reflect.Value, path string) ([]reflect.StructField, bool) {
+	var fs []reflect.StructField
+	var t reflect.Type
+	if v.IsValid() {
+		t = v.Type()
+	}
+	for path != "" {
+		if v.IsValid() {
+			t = v.Type()
+			if v = derefValue(v); v.IsValid() {
+				t = v.Type()
+			}
+		}
+		t = derefType(t)
+		if t == nil {
+			return fs, false
+		}
+		seg, rest, _ := strings.Cut(path, ".")
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := c.fieldByName(t, seg)
+			if !ok {
+				return fs, false
+			}
+			if fs = append(fs, f); rest == "" {
+				return fs, true
+			}
+			t = f.Type
+			if v.IsValid() {
+				v, _ = v.FieldByIndexErr(f.Index)
+			}
+		case reflect.Slice, reflect.Array:
+			t = t.Elem()
+			if n, ok := getNumber(seg); ok && v.IsValid() && n < v.Len() {
+				v = v.Index(n)
+			} else {
+				v = reflect.Value{}
+			}
+		case reflect.Map:
+			if k, ok := getKey(t, seg); ok && v.IsValid() {
+				v = v.MapIndex(k)
+			} else {
+				v = reflect.Value{}
+			}
+			t = t.Elem()
+		default:
+			return fs, false
+		}
+		path = rest
+	}
+	return fs, false
+}
+
+// checkRules returns the errors of the rules of t broken by v.
+func checkRules(t tag, v reflect.Value) []error {
+	var out []error
+	v = derefValue(v)
+	for _, o := range t.opts {
+		rule, param, _ := strings.Cut(o, "=")
+		var ok bool
+		switch rule {
+		case "required":
+			ok = v.IsValid() && !v.IsZero()
+		case "min", "max", "len":
+			ok = checkSize(rule, param, v)
+		case "oneof":
+			ok = !v.IsValid() || slices.Contains(strings.Fields(param), fmt.Sprint(v.Interface()))
+		case "regexp":
+			ok = checkRegexp(param, v)
+		default:
+			continue
+		}
+		if !ok {
+			out = append(out, &RuleError{Rule: rule, Param: param})
+		}
+	}
+	return out
+}
+
+// checkSize compares the number or length of v with param.
+func checkSize(rule, param string, v reflect.Value) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	var n float64
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String:
+		n = float64(len([]rune(v.String())))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n = float64(v.Len())
+	default:
+		return false
+	}
+	if rule == "len" {
+		return n == limit
+	}
+	if rule == "min" {
+		return n >= limit
+	}
+	return n <= limit
+}
+
+var regexps sync.Map // map[string]*regexp.Regexp
+
+func checkRegexp(expr string, v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if v.Kind() != reflect.String {
+		return false
+	}
+	re, ok := regexps.Load(expr)
+	if !ok {
+		r, err := regexp.Compile(expr)
+		if err != nil {
+			return false
+		}
+		re, _ = regexps.LoadOrStore(expr, r)
+	}
+	return re.(*regexp.Regexp).MatchString(v.String())
+}
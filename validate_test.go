@@ -0,0 +1,85 @@
+package rift_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ofabricio/rift"
+)
+
+func TestValidate(t *testing.T) {
+
+	type Address struct {
+		Street string `rift:",required,max=10"`
+		Zip    string `rift:",regexp=^[0-9]{5}$"`
+	}
+	type User struct {
+		Name      string    `rift:"name,required,min=2"`
+		Age       *int      `rift:",min=0,max=150"`
+		Role      string    `rift:",oneof=admin user"`
+		Tags      []string  `rift:",max=2"`
+		Code      string    `rift:",len=3"`
+		Addresses []Address `rift:",min=1"`
+	}
+
+	tt := []struct {
+		Desc string
+		When []rift.Node
+		Errs rift.ValidationError
+	}{
+		{
+			Desc: "valid",
+			When: []rift.Node{
+				rift.Path("Name", "Jo"),
+				rift.Path("Age", 30),
+				rift.Path("Role", "admin"),
+				rift.Path("Tags", []string{"a", "b"}),
+				rift.Path("Code", "abc"),
+				rift.Path("Addresses.0.Street", "Main"),
+				rift.Path("Addresses.0.Zip", "12345"),
+			},
+		},
+		{
+			Desc: "invalid",
+			When: []rift.Node{
+				rift.Path("name", ""),
+				rift.Path("Age", ptr(200)),
+				rift.Path("Role", "root"),
+				rift.Path("Tags", []string{"a", "b", "c"}),
+				rift.Path("Code", "ab"),
+				rift.Path("Addresses", []Address{}),
+				rift.Path("Addresses.0.Street", "Main Street 1"),
+				rift.Path("Addresses.0.Zip", "1234"),
+			},
+			Errs: rift.ValidationError{
+				"name":               {&rift.RuleError{Rule: "required"}, &rift.RuleError{Rule: "min", Param: "2"}},
+				"Age":                {&rift.RuleError{Rule: "max", Param: "150"}},
+				"Role":               {&rift.RuleError{Rule: "oneof", Param: "admin user"}},
+				"Tags":               {&rift.RuleError{Rule: "max", Param: "2"}},
+				"Code":               {&rift.RuleError{Rule: "len", Param: "3"}},
+				"Addresses":          {&rift.RuleError{Rule: "min", Param: "1"}},
+				"Addresses.0.Street": {&rift.RuleError{Rule: "max", Param: "10"}},
+				"Addresses.0.Zip":    {&rift.RuleError{Rule: "regexp", Param: "^[0-9]{5}$"}},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		var u User
+		_, err := rift.Apply(&u, tc.When)
+		if tc.Errs == nil {
+			assertEqual(t, nil, err, tc.Desc)
+			continue
+		}
+		assertEqual(t, tc.Errs, err, tc.Desc)
+		assertEqual(t, true, errors.Is(err, rift.ErrInvalid), tc.Desc)
+		assertEqual(t, User{}, u, "nothing set")
+	}
+
+	var u User
+	_, err := rift.TrySetPath(&u, "Role", "root")
+	assertEqual(t, "rift: invalid values: Role: breaks oneof=admin user", err.Error(), "single path")
+
+	_, err = rift.TrySetPath(&u, "Name", nil)
+	assertEqual(t, "rift: invalid values: Name: breaks required", err.Error(), "nil value")
+}